@@ -2,6 +2,7 @@ package apimanager
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/scalecloud/scalecloud.de-api/newslettermanager"
+	"github.com/scalecloud/scalecloud.de-api/ratelimitmanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
 	"github.com/scalecloud/scalecloud.de-api/stripemanager"
 	"github.com/scalecloud/scalecloud.de-api/stripemanager/secret"
 	"go.uber.org/zap"
@@ -27,10 +30,16 @@ type Api struct {
 	router         *gin.Engine
 	paymentHandler *stripemanager.PaymentHandler
 	webhookHandler *WebhookHandler
+	rateLimiter    *ratelimitmanager.RateLimiter
+	openAPISpec    []byte
 	validate       *validator.Validate
 	log            *zap.Logger
 }
 
+const contextKeyTokenDetails = "tokenDetails"
+
+const shutdownTimeout = 10 * time.Second
+
 type WebhookHandler struct {
 	StripeConnection *stripemanager.StripeConnection
 	Log              *zap.Logger
@@ -74,7 +83,7 @@ func InitAPI(log *zap.Logger, production bool, proxyIP string) (*Api, error) {
 		return &Api{}, err
 	}
 
-	err = mongoConnection.CheckDatabaseAndCollectionExists(context.Background())
+	err = mongoConnection.EnsureDatabasesAndCollections(context.Background())
 	if err != nil {
 		return &Api{}, err
 	}
@@ -94,6 +103,11 @@ func InitAPI(log *zap.Logger, production bool, proxyIP string) (*Api, error) {
 		return &Api{}, err
 	}
 
+	rateLimiter, err := ratelimitmanager.InitRateLimiter(log, mongoConnection)
+	if err != nil {
+		return &Api{}, err
+	}
+
 	validate := validator.New(validator.WithRequiredStructEnabled())
 
 	api := &Api{
@@ -112,8 +126,9 @@ func InitAPI(log *zap.Logger, production bool, proxyIP string) (*Api, error) {
 			StripeConnection: stripeConnection,
 			Log:              log.Named("webhookhandler"),
 		},
-		validate: validate,
-		log:      log.Named("apimanager"),
+		rateLimiter: rateLimiter,
+		validate:    validate,
+		log:         log.Named("apimanager"),
 	}
 	return api, nil
 }
@@ -125,21 +140,25 @@ func (api *Api) CloseMongoClient() {
 	}
 }
 
-func (api *Api) RunAPI() {
+// RunAPI serves requests until ctx is cancelled and stops the background jobs with it.
+func (api *Api) RunAPI(ctx context.Context) {
 	api.initHeaders()
 	api.initRoutes()
-	api.initCertificate()
+	api.initOpenAPI()
 	api.initTrustedProxies()
-	api.startListening()
+	api.startSeatVerificationSync(ctx)
+	api.initCertificate(ctx)
+	api.startListening(ctx)
 }
 
 func (api *Api) initHeaders() {
+	api.router.Use(api.requestIDRequired)
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:4200"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Baggage", "sentry-trace"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Baggage", "sentry-trace", requestmanager.HeaderRequestID, requestmanager.HeaderIdempotencyKey}
 	config.AllowCredentials = true
-	config.ExposeHeaders = []string{"Content-Length"}
+	config.ExposeHeaders = []string{"Content-Length", requestmanager.HeaderRequestID, requestmanager.HeaderIdempotentReplayed, "Retry-After"}
 	config.MaxAge = 12 * time.Hour
 	api.router.Use(cors.New(config))
 }
@@ -150,6 +169,8 @@ func (api *Api) initRoutes() {
 
 	api.log.Info("Setting up routes...")
 
+	api.router.GET(openAPIPath, api.getOpenAPI)
+
 	webhook := api.router.Group("/webhook/")
 	webhook.Use(api.StripeRequired)
 	{
@@ -157,55 +178,109 @@ func (api *Api) initRoutes() {
 	}
 
 	product := api.router.Group("/product/tiers")
+	product.Use(api.ipRateLimit(ratelimitmanager.GroupProduct))
 	{
-		product.GET("/nextcloud", cache.CachePage(store, time.Hour*24, api.getProductTiersNextcloud))
-		product.GET("/synology", cache.CachePage(store, time.Hour*24, api.getProductTiersSynology))
+		product.GET("/nextcloud", deprecated("/v1/products/nextcloud/tiers"), cache.CachePage(store, time.Hour*24, api.getProductTiersNextcloud))
+		product.GET("/synology", deprecated("/v1/products/synology/tiers"), cache.CachePage(store, time.Hour*24, api.getProductTiersSynology))
 	}
 
 	dashboard := api.router.Group("/dashboard")
-	dashboard.Use(api.authRequired)
+	dashboard.Use(api.ipRateLimit(ratelimitmanager.GroupDashboard), api.authRequired, api.uidRateLimit(ratelimitmanager.GroupDashboard), api.idempotencyRequired)
 	{
-		dashboard.GET("/subscriptions", api.getSubscriptionsOverview)
-		dashboard.GET("/subscription/:id", api.getSubscriptionByID)
-		dashboard.GET("/subscription/:id/cancel-state", api.getCancelState)
-		dashboard.POST("/subscription/permission", api.GetMyPermission)
-		dashboard.POST("/subscription/list-seats", api.getSubscriptionListSeats)
-		dashboard.POST("/subscription/seat-detail", api.getSubscriptionSeatDetail)
-		dashboard.POST("/subscription/update-seat", api.getSubscriptionUpdateSeat)
-		dashboard.POST("/subscription/add-seat", api.getSubscriptionAddSeat)
-		dashboard.POST("/subscription/delete-seat", api.getSubscriptionRemoveSeat)
-		dashboard.POST("/subscription/invoices", api.getSubscriptionInvoices)
-		dashboard.POST("/subscription/billing-address", api.getBillingAddress)
-		dashboard.POST("/subscription/update-billing-address", api.updateBillingAddress)
-		dashboard.POST("/get-payment-method-overview", api.getPaymentMethodOverview)
-		dashboard.POST("/get-change-payment-setup-intent", api.getChangePaymentSetupIntent)
-		dashboard.POST("/resume-subscription", api.resumeSubscription)
-		dashboard.POST("/cancel-subscription", api.cancelSubscription)
-		dashboard.GET("/billing-portal", api.handleBillingPortal)
+		dashboard.GET("/subscriptions", deprecated("/v1/subscriptions"), api.getSubscriptionsOverview)
+		dashboard.GET("/subscription/:id", deprecated("/v1/subscriptions/{id}"), api.requirePermission(mongomanager.PermissionSubscriptionRead), api.getSubscriptionByID)
+		dashboard.GET("/subscription/:id/cancel-state", deprecated("/v1/subscriptions/{id}/cancel-state"), api.requirePermission(mongomanager.PermissionSubscriptionCancel), api.getCancelState)
+		dashboard.POST("/subscription/permission", deprecated("/v1/subscriptions/{id}/permission"), api.GetMyPermission)
+		dashboard.POST("/subscription/list-seats", deprecated("/v1/subscriptions/{id}/seats"), requireBodyPermission[stripemanager.ListSeatRequest](api, mongomanager.PermissionSeatsRead), api.getSubscriptionListSeats)
+		dashboard.POST("/subscription/seat-detail", deprecated("/v1/subscriptions/{id}/seats/{uid}"), requireBodyPermission[stripemanager.SeatDetailRequest](api, mongomanager.PermissionSeatsRead), api.getSubscriptionSeatDetail)
+		dashboard.POST("/subscription/update-seat", deprecated("/v1/subscriptions/{id}/seats/{uid}"), requireBodyPermission[stripemanager.UpdateSeatDetailRequest](api, mongomanager.PermissionSeatsWrite), api.getSubscriptionUpdateSeat)
+		dashboard.POST("/subscription/add-seat", deprecated("/v1/subscriptions/{id}/seats"), requireBodyPermission[stripemanager.AddSeatRequest](api, mongomanager.PermissionSeatsWrite), api.getSubscriptionAddSeat)
+		dashboard.POST("/subscription/delete-seat", deprecated("/v1/subscriptions/{id}/seats/{uid}"), requireBodyPermission[stripemanager.DeleteSeatRequest](api, mongomanager.PermissionSeatsWrite), api.getSubscriptionRemoveSeat)
+		dashboard.POST("/subscription/invoices", deprecated("/v1/subscriptions/{id}/invoices"), requireBodyPermission[stripemanager.ListInvoicesRequest](api, mongomanager.PermissionInvoicesRead), api.getSubscriptionInvoices)
+		dashboard.POST("/subscription/billing-address", deprecated("/v1/subscriptions/{id}/billing-address"), requireBodyPermission[stripemanager.BillingAddressRequest](api, mongomanager.PermissionBillingRead), api.getBillingAddress)
+		dashboard.POST("/subscription/update-billing-address", deprecated("/v1/subscriptions/{id}/billing-address"), requireBodyPermission[stripemanager.UpdateBillingAddressRequest](api, mongomanager.PermissionBillingWrite), api.updateBillingAddress)
+		dashboard.POST("/get-payment-method-overview", deprecated("/v1/payment-method"), api.getPaymentMethodOverview)
+		dashboard.POST("/get-change-payment-setup-intent", deprecated("/v1/payment-method/setup-intents"), api.getChangePaymentSetupIntent)
+		dashboard.POST("/resume-subscription", deprecated("/v1/subscriptions/{id}/resume"), requireBodyPermission[stripemanager.SubscriptionResumeRequest](api, mongomanager.PermissionSubscriptionCancel), api.resumeSubscription)
+		dashboard.POST("/cancel-subscription", deprecated("/v1/subscriptions/{id}/cancel"), requireBodyPermission[stripemanager.SubscriptionCancelRequest](api, mongomanager.PermissionSubscriptionCancel), api.cancelSubscription)
+		dashboard.GET("/billing-portal", deprecated("/v1/billing-portal"), api.handleBillingPortal)
 	}
 	checkoutIntegration := api.router.Group("/checkout-integration")
-	checkoutIntegration.Use(api.authRequired)
+	checkoutIntegration.Use(api.ipRateLimit(ratelimitmanager.GroupCheckout), api.authRequired, api.uidRateLimit(ratelimitmanager.GroupCheckout), api.idempotencyRequired)
 	{
-		checkoutIntegration.POST("/create-checkout-subscription", api.createCheckoutSubscription)
-		checkoutIntegration.POST("/get-checkout-product", api.getCheckoutProduct)
+		checkoutIntegration.POST("/create-checkout-subscription", deprecated("/v1/checkout/subscriptions"), api.createCheckoutSubscription)
+		checkoutIntegration.POST("/get-checkout-product", deprecated("/v1/checkout/products/{id}"), api.getCheckoutProduct)
 	}
 	checkoutSetupIntent := api.router.Group("/checkout-setup-intent")
-	checkoutSetupIntent.Use(api.authRequired)
+	checkoutSetupIntent.Use(api.ipRateLimit(ratelimitmanager.GroupCheckout), api.authRequired, api.uidRateLimit(ratelimitmanager.GroupCheckout), api.idempotencyRequired)
 	{
-		checkoutSetupIntent.POST("/create-setup-intent", api.createCheckoutSetupIntent)
+		checkoutSetupIntent.POST("/create-setup-intent", deprecated("/v1/checkout/setup-intents"), api.createCheckoutSetupIntent)
 	}
 	newsletters := api.router.Group("/newsletter")
+	newsletters.Use(api.ipRateLimit(ratelimitmanager.GroupNewsletter))
 	{
 		newsletters.POST("/subscribe", api.newsletterSubscribe)
 		newsletters.POST("/confirm", api.newsletterConfirm)
 		newsletters.POST("/unsubscribe", api.newsletterUnsubscribe)
 	}
+
+	v1 := api.router.Group("/v1")
+	v1Products := v1.Group("/products")
+	v1Products.Use(api.ipRateLimit(ratelimitmanager.GroupProduct))
+	{
+		v1Products.GET("/:productType/tiers", cache.CachePage(store, time.Hour*24, api.v1GetProductTiers))
+	}
+	v1Dashboard := v1.Group("")
+	v1Dashboard.Use(api.ipRateLimit(ratelimitmanager.GroupDashboard), api.authRequired, api.uidRateLimit(ratelimitmanager.GroupDashboard), api.idempotencyRequired)
+	{
+		v1Dashboard.GET("/subscriptions", api.getSubscriptionsOverview)
+		v1Dashboard.GET("/subscriptions/:id", api.requirePermission(mongomanager.PermissionSubscriptionRead), api.getSubscriptionByID)
+		v1Dashboard.GET("/subscriptions/:id/cancel-state", api.requirePermission(mongomanager.PermissionSubscriptionCancel), api.getCancelState)
+		v1Dashboard.POST("/subscriptions/:id/cancel", api.requirePermission(mongomanager.PermissionSubscriptionCancel), api.v1CancelSubscription)
+		v1Dashboard.POST("/subscriptions/:id/resume", api.requirePermission(mongomanager.PermissionSubscriptionCancel), api.v1ResumeSubscription)
+		v1Dashboard.GET("/subscriptions/:id/permission", api.v1GetMyPermission)
+		v1Dashboard.GET("/subscriptions/:id/permissions", api.v1GetMyPermissions)
+		v1Dashboard.POST("/subscriptions/:id/leave", api.v1LeaveSubscription)
+		v1Dashboard.GET("/subscriptions/:id/seats", api.requirePermission(mongomanager.PermissionSeatsRead), api.v1ListSeats)
+		v1Dashboard.GET("/subscriptions/:id/seats/export", api.requirePermission(mongomanager.PermissionSeatsRead), api.v1ExportSeats)
+		v1Dashboard.POST("/subscriptions/:id/seats", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1AddSeat)
+		v1Dashboard.POST("/subscriptions/:id/seats/import", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1ImportSeats)
+		v1Dashboard.POST("/subscriptions/:id/seats/bulk-delete", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1DeleteSeats)
+		v1Dashboard.GET("/subscriptions/:id/seats/:uid", api.requirePermission(mongomanager.PermissionSeatsRead), api.v1GetSeat)
+		v1Dashboard.PUT("/subscriptions/:id/seats/:uid", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1UpdateSeat)
+		v1Dashboard.PUT("/subscriptions/:id/seats/:uid/roles", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1UpdateSeatRoles)
+		v1Dashboard.DELETE("/subscriptions/:id/seats/:uid", api.requirePermission(mongomanager.PermissionSeatsWrite), api.v1DeleteSeat)
+		v1Dashboard.GET("/subscriptions/:id/invoices", api.requirePermission(mongomanager.PermissionInvoicesRead), api.v1ListInvoices)
+		v1Dashboard.GET("/subscriptions/:id/upcoming-invoice", api.requirePermission(mongomanager.PermissionInvoicesRead), api.v1GetUpcomingInvoice)
+		v1Dashboard.GET("/subscriptions/:id/invoices/archive", api.requirePermission(mongomanager.PermissionInvoicesRead), api.v1GetInvoiceArchive)
+		v1Dashboard.GET("/subscriptions/:id/invoices/:invoiceID/pdf", api.requirePermission(mongomanager.PermissionInvoicesRead), api.v1GetInvoicePDF)
+		v1Dashboard.GET("/subscriptions/:id/billing-address", api.requirePermission(mongomanager.PermissionBillingRead), api.v1GetBillingAddress)
+		v1Dashboard.PUT("/subscriptions/:id/billing-address", api.requirePermission(mongomanager.PermissionBillingWrite), api.v1UpdateBillingAddress)
+		v1Dashboard.GET("/subscriptions/:id/owner-transfer", api.requirePermission(mongomanager.PermissionOwnerTransfer), api.v1GetOwnerTransfer)
+		v1Dashboard.POST("/subscriptions/:id/owner-transfer", api.requirePermission(mongomanager.PermissionOwnerTransfer), api.v1RequestOwnerTransfer)
+		v1Dashboard.DELETE("/subscriptions/:id/owner-transfer", api.requirePermission(mongomanager.PermissionOwnerTransfer), api.v1CancelOwnerTransfer)
+		v1Dashboard.POST("/owner-transfers/accept", api.v1AcceptOwnerTransfer)
+		v1Dashboard.POST("/owner-transfers/decline", api.v1DeclineOwnerTransfer)
+		v1Dashboard.GET("/subscriptions/:id/audit-log", api.requirePermission(mongomanager.PermissionAuditLogRead), api.v1ListAuditLog)
+		v1Dashboard.GET("/payment-method", api.getPaymentMethodOverview)
+		v1Dashboard.POST("/payment-method/setup-intents", api.getChangePaymentSetupIntent)
+		v1Dashboard.GET("/billing-portal", api.handleBillingPortal)
+	}
+	v1Checkout := v1.Group("/checkout")
+	v1Checkout.Use(api.ipRateLimit(ratelimitmanager.GroupCheckout), api.authRequired, api.uidRateLimit(ratelimitmanager.GroupCheckout), api.idempotencyRequired)
+	{
+		v1Checkout.POST("/subscriptions", api.createCheckoutSubscription)
+		v1Checkout.GET("/products/:id", api.v1GetCheckoutProduct)
+		v1Checkout.POST("/setup-intents", api.createCheckoutSetupIntent)
+		v1Checkout.GET("/billing-address", api.v1GetCheckoutBillingAddress)
+		v1Checkout.PUT("/billing-address", api.v1UpdateCheckoutBillingAddress)
+	}
 }
 
-func (api *Api) initCertificate() {
+func (api *Api) initCertificate(ctx context.Context) {
 	if api.production {
 		api.log.Info("Setting up certificate...")
-		err := autotls.Run(api.router, "api.scalecloud.de")
+		err := autotls.RunWithContext(ctx, api.router, "api.scalecloud.de")
 		if err != nil {
 			api.log.Error("Could not setup certificate", zap.Error(err))
 			panic(err)
@@ -236,36 +311,53 @@ func (api *Api) initTrustedProxies() {
 	}
 }
 
-func (api *Api) startListening() {
+func (api *Api) startListening(ctx context.Context) {
 	api.log.Info("Starting listening for requests")
-	err := api.router.Run(":15000")
-	if err != nil {
+	server := &http.Server{
+		Addr:    ":15000",
+		Handler: api.router,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			api.log.Error("Could not shut down listening for requests", zap.Error(err))
+		}
+	}()
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		api.log.Error("Could not start listening for requests", zap.Error(err))
 	}
 }
 
 func (api *Api) authRequired(c *gin.Context) {
-	token, err := firebasemanager.GetBearerToken(c)
+	tokenDetails, err := api.paymentHandler.FirebaseConnection.GetTokenDetails(c)
 	if err != nil {
-		api.log.Warn("Unauthorized", zap.Error(err))
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		api.requestLog(c).Warn("Unauthorized", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(c, "unauthorized"))
 		return
 	}
-	err = api.paymentHandler.FirebaseConnection.VerifyIDToken(c, token)
+	c.Set(contextKeyTokenDetails, tokenDetails)
+	err = api.paymentHandler.SyncSeatEMailVerified(c, tokenDetails)
 	if err != nil {
-		api.log.Warn("Unauthorized", zap.String("token:", token))
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-		return
+		api.requestLog(c).Warn("Error syncing seat email verification", zap.String("uid", tokenDetails.UID), zap.Error(err))
 	}
-	api.log.Debug("Authenticated", zap.String("token:", token))
+	api.requestLog(c).Debug("Authenticated", zap.String("uid", tokenDetails.UID))
 	c.Next()
 }
 
 func (api *Api) handleTokenDetails(c *gin.Context) (firebasemanager.TokenDetails, error) {
+	if value, exists := c.Get(contextKeyTokenDetails); exists {
+		if tokenDetails, ok := value.(firebasemanager.TokenDetails); ok {
+			return tokenDetails, nil
+		}
+	}
 	tokenDetails, err := api.paymentHandler.FirebaseConnection.GetTokenDetails(c)
 	if err != nil {
-		api.log.Error("Error getting token details", zap.Error(err))
-		c.SecureJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		api.requestLog(c).Error("Error getting token details", zap.Error(err))
+		c.SecureJSON(http.StatusUnauthorized, errorResponse(c, err.Error()))
 		return firebasemanager.TokenDetails{}, err
 	}
 	return tokenDetails, nil
@@ -274,39 +366,46 @@ func (api *Api) handleTokenDetails(c *gin.Context) (firebasemanager.TokenDetails
 func (api *Api) handleBind(c *gin.Context, s interface{}) bool {
 	err := c.BindJSON(s)
 	if err != nil {
-		api.log.Warn("Error binding json", zap.Error(err))
-		c.SecureJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		api.requestLog(c).Warn("Error binding json", zap.Error(err))
+		c.SecureJSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return false
 	}
-	api.log.Info("Request", zap.Any("request", s))
+	api.requestLog(c).Info("Request", zap.Any("request", s))
 	return true
 }
 
 func (api *Api) validateReply(c *gin.Context, err error, reply interface{}) bool {
+	if !api.handleReplyError(c, err) {
+		return false
+	}
+	return api.validateStruct(c, reply)
+}
+
+func (api *Api) handleReplyError(c *gin.Context, err error) bool {
 	if err != nil {
 		if err.Error() == http.StatusText(http.StatusForbidden) {
-			api.log.Warn("Access denied", zap.Error(err))
-			c.SecureJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			api.requestLog(c).Warn("Access denied", zap.Error(err))
+			c.SecureJSON(http.StatusForbidden, errorResponse(c, err.Error()))
 			return false
 		} else {
-			api.log.Error("Validate reply", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			api.requestLog(c).Error("Validate reply", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 			return false
 		}
 	}
-	return api.validateStruct(c, reply)
+	return true
 }
 
 func (api *Api) validateStruct(c *gin.Context, s interface{}) bool {
 	if s == nil {
-		api.log.Error("Struct is nil")
-		c.SecureJSON(http.StatusBadRequest, gin.H{"error": "Struct is nil"})
+		api.requestLog(c).Error("Struct is nil")
+		c.SecureJSON(http.StatusBadRequest, errorResponse(c, "Struct is nil"))
 		return false
 	}
 	err := api.validate.Struct(s)
 	if err != nil {
-		api.log.Error("Error validating struct", zap.Error(err))
-		c.SecureJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		api.requestLog(c).Error("Error validating struct", zap.Error(err))
+		c.SecureJSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return false
 	}
 	return true
@@ -319,6 +418,6 @@ func (api *Api) validateAndWriteReply(c *gin.Context, err error, reply interface
 }
 
 func (api *Api) writeReply(c *gin.Context, reply interface{}) {
-	api.log.Info("Reply", zap.Any("reply", reply))
+	api.requestLog(c).Info("Reply", zap.Any("reply", reply))
 	c.IndentedJSON(http.StatusOK, reply)
 }
@@ -0,0 +1,136 @@
+package apimanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
+	"go.uber.org/zap"
+)
+
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (writer *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	writer.body.Write(data)
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *idempotencyResponseWriter) WriteString(data string) (int, error) {
+	writer.body.WriteString(data)
+	return writer.ResponseWriter.WriteString(data)
+}
+
+func (api *Api) idempotencyRequired(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		c.Next()
+		return
+	}
+	key := c.Request.Header.Get(requestmanager.HeaderIdempotencyKey)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if !requestmanager.IsValidIdempotencyKey(key) {
+		api.requestLog(c).Warn("Invalid idempotency key", zap.String("idempotencyKey", key))
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, "invalid "+requestmanager.HeaderIdempotencyKey+" header"))
+		return
+	}
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		c.Abort()
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		api.requestLog(c).Warn("Error reading request body", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, "error reading request body"))
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	requestHash := sha256.Sum256(body)
+	record := mongomanager.IdempotencyRecord{
+		Key:         key,
+		UID:         tokenDetails.UID,
+		Route:       c.FullPath(),
+		RequestHash: hex.EncodeToString(requestHash[:]),
+		CreatedAt:   time.Now(),
+	}
+	err = api.paymentHandler.MongoConnection.CreateIdempotencyRecord(c, record)
+	if err != nil {
+		if errors.Is(err, mongomanager.ErrIdempotencyRecordExists) {
+			api.replayIdempotentRequest(c, record)
+			return
+		}
+		api.requestLog(c).Error("Error creating idempotency record", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	c.Set(requestmanager.ContextKeyIdempotencyKey, tokenDetails.UID+"-"+key)
+	writer := &idempotencyResponseWriter{
+		ResponseWriter: c.Writer,
+		body:           &bytes.Buffer{},
+	}
+	c.Writer = writer
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		err := api.paymentHandler.MongoConnection.DeleteIdempotencyRecord(context.Background(), record.UID, record.Key)
+		if err != nil {
+			api.requestLog(c).Error("Error deleting idempotency record", zap.Error(err))
+		}
+	}()
+	c.Next()
+
+	if !writer.Written() || writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+	record.Completed = true
+	record.StatusCode = writer.Status()
+	record.ContentType = writer.Header().Get("Content-Type")
+	record.Body = writer.body.Bytes()
+	err = api.paymentHandler.MongoConnection.CompleteIdempotencyRecord(c, record)
+	if err != nil {
+		api.requestLog(c).Error("Error completing idempotency record", zap.Error(err))
+		return
+	}
+	completed = true
+}
+
+func (api *Api) replayIdempotentRequest(c *gin.Context, record mongomanager.IdempotencyRecord) {
+	stored, err := api.paymentHandler.MongoConnection.GetIdempotencyRecord(c, record.UID, record.Key)
+	if err != nil {
+		api.requestLog(c).Error("Error getting idempotency record", zap.Error(err))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(c, "error getting idempotency record"))
+		return
+	}
+	if stored.Route != record.Route || stored.RequestHash != record.RequestHash {
+		api.requestLog(c).Warn("Idempotency key reused with a different request", zap.String("idempotencyKey", record.Key))
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse(c, requestmanager.HeaderIdempotencyKey+" was already used for a different request"))
+		return
+	}
+	if !stored.Completed {
+		api.requestLog(c).Warn("Idempotent request is still in progress", zap.String("idempotencyKey", record.Key))
+		c.AbortWithStatusJSON(http.StatusConflict, errorResponse(c, "a request with this "+requestmanager.HeaderIdempotencyKey+" is still being processed"))
+		return
+	}
+	api.requestLog(c).Info("Replaying idempotent request", zap.String("idempotencyKey", record.Key))
+	c.Header(requestmanager.HeaderIdempotentReplayed, "true")
+	c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+	c.Abort()
+}
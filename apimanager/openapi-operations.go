@@ -0,0 +1,449 @@
+package apimanager
+
+import (
+	"github.com/scalecloud/scalecloud.de-api/newslettermanager"
+	"github.com/scalecloud/scalecloud.de-api/stripemanager"
+)
+
+var openAPIOperations = map[string]openAPIOperation{
+	"GET " + openAPIPath: {
+		summary: "OpenAPI specification of this API",
+		tag:     "meta",
+		reply:   map[string]any{},
+	},
+	"POST /webhook/stripe": {
+		summary: "Stripe webhook endpoint, requires a valid Stripe-Signature header",
+		tag:     "webhook",
+	},
+	"GET /product/tiers/nextcloud": {
+		summary:    "List Nextcloud product tiers",
+		deprecated: true,
+		tag:        "product",
+		reply:      stripemanager.ProductTiersReply{},
+	},
+	"GET /product/tiers/synology": {
+		summary:    "List Synology product tiers",
+		deprecated: true,
+		tag:        "product",
+		reply:      stripemanager.ProductTiersReply{},
+	},
+	"GET /dashboard/subscriptions": {
+		summary:    "List subscriptions of the caller",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      []stripemanager.SubscriptionOverviewReply{},
+	},
+	"GET /dashboard/subscription/:id": {
+		summary:    "Get subscription details",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      stripemanager.SubscriptionDetailReply{},
+	},
+	"GET /dashboard/subscription/:id/cancel-state": {
+		summary:    "Get cancel state of a subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      stripemanager.CancelStateReply{},
+	},
+	"POST /dashboard/subscription/permission": {
+		summary:    "Get the seat of the caller",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.PermissionRequest{},
+		reply:      stripemanager.PermissionReply{},
+	},
+	"POST /dashboard/subscription/list-seats": {
+		summary:    "List seats of a subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.ListSeatRequest{},
+		reply:      stripemanager.ListSeatReply{},
+	},
+	"POST /dashboard/subscription/seat-detail": {
+		summary:    "Get seat details",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.SeatDetailRequest{},
+		reply:      stripemanager.SeatDetailReply{},
+	},
+	"POST /dashboard/subscription/update-seat": {
+		summary:    "Update a seat",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.UpdateSeatDetailRequest{},
+		reply:      stripemanager.UpdateSeatDetailReply{},
+	},
+	"POST /dashboard/subscription/add-seat": {
+		summary:    "Invite a user to a seat",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.AddSeatRequest{},
+		reply:      stripemanager.AddSeatReply{},
+	},
+	"POST /dashboard/subscription/delete-seat": {
+		summary:    "Remove a seat",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.DeleteSeatRequest{},
+		reply:      stripemanager.DeleteSeatReply{},
+	},
+	"POST /dashboard/subscription/invoices": {
+		summary:    "List invoices of a subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.ListInvoicesRequest{},
+		reply:      stripemanager.ListInvoicesReply{},
+	},
+	"POST /dashboard/subscription/billing-address": {
+		summary:    "Get billing address of a subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.BillingAddressRequest{},
+		reply:      stripemanager.BillingAddressReply{},
+	},
+	"POST /dashboard/subscription/update-billing-address": {
+		summary:    "Update billing address of a subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.UpdateBillingAddressRequest{},
+		reply:      stripemanager.UpdateBillingAddressReply{},
+	},
+	"POST /dashboard/get-payment-method-overview": {
+		summary:    "Get default payment method of the caller",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      stripemanager.PaymentMethodOverviewReply{},
+	},
+	"POST /dashboard/get-change-payment-setup-intent": {
+		summary:    "Create a setup intent to change the payment method",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      stripemanager.ChangePaymentReply{},
+	},
+	"POST /dashboard/resume-subscription": {
+		summary:    "Resume a canceled subscription",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.SubscriptionResumeRequest{},
+		reply:      stripemanager.SubscriptionResumeReply{},
+	},
+	"POST /dashboard/cancel-subscription": {
+		summary:    "Cancel a subscription at period end",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		request:    stripemanager.SubscriptionCancelRequest{},
+		reply:      stripemanager.SubscriptionCancelReply{},
+	},
+	"GET /dashboard/billing-portal": {
+		summary:    "Create a Stripe billing portal session",
+		deprecated: true,
+		tag:        "dashboard",
+		auth:       true,
+		reply:      stripemanager.BillingPortalReply{},
+	},
+	"POST /checkout-integration/create-checkout-subscription": {
+		summary:    "Create a subscription",
+		deprecated: true,
+		tag:        "checkout",
+		auth:       true,
+		request:    stripemanager.CheckoutCreateSubscriptionRequest{},
+		reply:      stripemanager.CheckoutCreateSubscriptionReply{},
+	},
+	"POST /checkout-integration/get-checkout-product": {
+		summary:    "Get product details for checkout",
+		deprecated: true,
+		tag:        "checkout",
+		auth:       true,
+		request:    stripemanager.CheckoutProductRequest{},
+		reply:      stripemanager.CheckoutProductReply{},
+	},
+	"POST /checkout-setup-intent/create-setup-intent": {
+		summary:    "Create a setup intent for checkout",
+		deprecated: true,
+		tag:        "checkout",
+		auth:       true,
+		request:    stripemanager.CheckoutSetupIntentRequest{},
+		reply:      stripemanager.CheckoutSetupIntentReply{},
+	},
+	"POST /newsletter/subscribe": {
+		summary: "Subscribe to the newsletter",
+		tag:     "newsletter",
+		request: newslettermanager.NewsletterSubscribeRequest{},
+		reply:   newslettermanager.NewsletterSubscribeReply{},
+	},
+	"POST /newsletter/confirm": {
+		summary: "Confirm a newsletter subscription",
+		tag:     "newsletter",
+		request: newslettermanager.NewsletterConfirmRequest{},
+		reply:   newslettermanager.NewsletterConfirmReply{},
+	},
+	"POST /newsletter/unsubscribe": {
+		summary: "Unsubscribe from the newsletter",
+		tag:     "newsletter",
+		request: newslettermanager.NewsletterUnsubscribeRequest{},
+		reply:   newslettermanager.NewsletterUnsubscribeReply{},
+	},
+	"GET /v1/products/:productType/tiers": {
+		summary: "List product tiers of a product type",
+		tag:     "product",
+		reply:   stripemanager.ProductTiersReply{},
+	},
+	"GET /v1/subscriptions": {
+		summary: "List subscriptions of the caller",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   []stripemanager.SubscriptionOverviewReply{},
+	},
+	"GET /v1/subscriptions/:id": {
+		summary: "Get subscription details",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.SubscriptionDetailReply{},
+	},
+	"GET /v1/subscriptions/:id/cancel-state": {
+		summary: "Get cancel state of a subscription",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.CancelStateReply{},
+	},
+	"POST /v1/subscriptions/:id/cancel": {
+		summary: "Cancel a subscription at period end",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.SubscriptionCancelReply{},
+	},
+	"POST /v1/subscriptions/:id/resume": {
+		summary: "Resume a canceled subscription",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.SubscriptionResumeReply{},
+	},
+	"GET /v1/subscriptions/:id/permission": {
+		summary: "Get the seat of the caller",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.PermissionReply{},
+	},
+	"GET /v1/subscriptions/:id/permissions": {
+		summary: "Get the roles and effective permissions of the caller",
+		tag:     "subscriptions",
+		auth:    true,
+		reply:   stripemanager.EffectivePermissionsReply{},
+	},
+	"POST /v1/subscriptions/:id/leave": {
+		summary: "Remove the caller's own seat from a subscription",
+		tag:     "seats",
+		auth:    true,
+		reply:   stripemanager.LeaveSubscriptionReply{},
+	},
+	"GET /v1/subscriptions/:id/seats": {
+		summary: "List seats of a subscription",
+		tag:     "seats",
+		auth:    true,
+		query:   []string{"pageIndex", "pageSize", "search", "role", "emailVerified", "sortBy", "sortDirection", "cursor"},
+		reply:   stripemanager.ListSeatReply{},
+	},
+	"POST /v1/subscriptions/:id/seats": {
+		summary: "Invite a user to a seat",
+		tag:     "seats",
+		auth:    true,
+		request: stripemanager.AddSeatRequest{},
+		reply:   stripemanager.AddSeatReply{},
+	},
+	"GET /v1/subscriptions/:id/seats/export": {
+		summary: "Export all seats as CSV or JSON, selected by the format query parameter",
+		tag:     "seats",
+		auth:    true,
+		query:   []string{"format"},
+		reply:   []stripemanager.ExportedSeat{},
+	},
+	"POST /v1/subscriptions/:id/seats/import": {
+		summary: "Invite multiple users from a JSON body or a CSV file with the columns email and roles",
+		tag:     "seats",
+		auth:    true,
+		request: stripemanager.ImportSeatsRequest{},
+		reply:   stripemanager.ImportSeatsReply{},
+	},
+	"POST /v1/subscriptions/:id/seats/bulk-delete": {
+		summary: "Remove multiple seats at once, the owner seat is never removed",
+		tag:     "seats",
+		auth:    true,
+		request: stripemanager.DeleteSeatsRequest{},
+		reply:   stripemanager.DeleteSeatsReply{},
+	},
+	"GET /v1/subscriptions/:id/seats/:uid": {
+		summary: "Get seat details",
+		tag:     "seats",
+		auth:    true,
+		reply:   stripemanager.SeatDetailReply{},
+	},
+	"PUT /v1/subscriptions/:id/seats/:uid": {
+		summary: "Update the roles of a seat",
+		tag:     "seats",
+		auth:    true,
+		request: stripemanager.SeatRolesUpdate{},
+		reply:   stripemanager.UpdateSeatDetailReply{},
+	},
+	"PUT /v1/subscriptions/:id/seats/:uid/roles": {
+		summary: "Replace the roles of a seat",
+		tag:     "seats",
+		auth:    true,
+		request: stripemanager.UpdateSeatRolesRequest{},
+		reply:   stripemanager.UpdateSeatRolesReply{},
+	},
+	"DELETE /v1/subscriptions/:id/seats/:uid": {
+		summary: "Remove a seat",
+		tag:     "seats",
+		auth:    true,
+		reply:   stripemanager.DeleteSeatReply{},
+	},
+	"GET /v1/subscriptions/:id/invoices": {
+		summary: "List invoices of a subscription, pass nextCursor as startingAfter or prevCursor as endingBefore to page",
+		tag:     "invoices",
+		auth:    true,
+		query:   []string{"pageSize", "startingAfter", "endingBefore"},
+		reply:   stripemanager.ListInvoicesReply{},
+	},
+	"GET /v1/subscriptions/:id/upcoming-invoice": {
+		summary: "Preview the next invoice of a subscription including prorations, discounts and taxes",
+		tag:     "invoices",
+		auth:    true,
+		reply:   stripemanager.UpcomingInvoiceReply{},
+	},
+	"GET /v1/subscriptions/:id/invoices/archive": {
+		summary:     "Download all invoice PDFs of a year as ZIP archive",
+		tag:         "invoices",
+		auth:        true,
+		query:       []string{"year"},
+		reply:       []byte{},
+		contentType: "application/zip",
+	},
+	"GET /v1/subscriptions/:id/invoices/:invoiceID/pdf": {
+		summary:     "Download the PDF of an invoice",
+		tag:         "invoices",
+		auth:        true,
+		reply:       []byte{},
+		contentType: "application/pdf",
+	},
+	"GET /v1/subscriptions/:id/billing-address": {
+		summary: "Get billing address of a subscription",
+		tag:     "billing",
+		auth:    true,
+		reply:   stripemanager.BillingAddressReply{},
+	},
+	"PUT /v1/subscriptions/:id/billing-address": {
+		summary: "Update billing address of a subscription",
+		tag:     "billing",
+		auth:    true,
+		request: stripemanager.UpdateBillingAddressRequest{},
+		reply:   stripemanager.UpdateBillingAddressReply{},
+	},
+	"GET /v1/subscriptions/:id/owner-transfer": {
+		summary: "Get the pending owner transfer of a subscription",
+		tag:     "owner-transfer",
+		auth:    true,
+		reply:   stripemanager.OwnerTransferReply{},
+	},
+	"POST /v1/subscriptions/:id/owner-transfer": {
+		summary: "Request an owner transfer to another seat, the new owner has to accept it",
+		tag:     "owner-transfer",
+		auth:    true,
+		request: stripemanager.OwnerTransferRequest{},
+		reply:   stripemanager.OwnerTransferReply{},
+	},
+	"DELETE /v1/subscriptions/:id/owner-transfer": {
+		summary: "Cancel the pending owner transfer of a subscription",
+		tag:     "owner-transfer",
+		auth:    true,
+		reply:   stripemanager.OwnerTransferReply{},
+	},
+	"POST /v1/owner-transfers/accept": {
+		summary: "Accept an owner transfer with the token from the invitation E-Mail",
+		tag:     "owner-transfer",
+		auth:    true,
+		request: stripemanager.OwnerTransferTokenRequest{},
+		reply:   stripemanager.OwnerTransferReply{},
+	},
+	"POST /v1/owner-transfers/decline": {
+		summary: "Decline an owner transfer with the token from the invitation E-Mail",
+		tag:     "owner-transfer",
+		auth:    true,
+		request: stripemanager.OwnerTransferTokenRequest{},
+		reply:   stripemanager.OwnerTransferReply{},
+	},
+	"GET /v1/subscriptions/:id/audit-log": {
+		summary: "List audit log entries of a subscription, newest first",
+		tag:     "audit",
+		auth:    true,
+		query:   []string{"pageSize", "action", "cursor"},
+		reply:   stripemanager.AuditLogReply{},
+	},
+	"GET /v1/payment-method": {
+		summary: "Get default payment method of the caller",
+		tag:     "billing",
+		auth:    true,
+		reply:   stripemanager.PaymentMethodOverviewReply{},
+	},
+	"POST /v1/payment-method/setup-intents": {
+		summary: "Create a setup intent to change the payment method",
+		tag:     "billing",
+		auth:    true,
+		reply:   stripemanager.ChangePaymentReply{},
+	},
+	"GET /v1/billing-portal": {
+		summary: "Create a Stripe billing portal session",
+		tag:     "billing",
+		auth:    true,
+		reply:   stripemanager.BillingPortalReply{},
+	},
+	"POST /v1/checkout/subscriptions": {
+		summary: "Create a subscription",
+		tag:     "checkout",
+		auth:    true,
+		request: stripemanager.CheckoutCreateSubscriptionRequest{},
+		reply:   stripemanager.CheckoutCreateSubscriptionReply{},
+	},
+	"GET /v1/checkout/products/:id": {
+		summary: "Get product details for checkout",
+		tag:     "checkout",
+		auth:    true,
+		query:   []string{"promotionCode"},
+		reply:   stripemanager.CheckoutProductReply{},
+	},
+	"POST /v1/checkout/setup-intents": {
+		summary: "Create a setup intent for checkout",
+		tag:     "checkout",
+		auth:    true,
+		request: stripemanager.CheckoutSetupIntentRequest{},
+		reply:   stripemanager.CheckoutSetupIntentReply{},
+	},
+	"GET /v1/checkout/billing-address": {
+		summary: "Get the billing address used for checkout",
+		tag:     "checkout",
+		auth:    true,
+		reply:   stripemanager.CheckoutBillingAddressReply{},
+	},
+	"PUT /v1/checkout/billing-address": {
+		summary: "Update the billing address used for checkout",
+		tag:     "checkout",
+		auth:    true,
+		request: stripemanager.UpdateCheckoutBillingAddressRequest{},
+		reply:   stripemanager.CheckoutBillingAddressReply{},
+	},
+}
@@ -0,0 +1,389 @@
+package apimanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const openAPIPath = "/openapi.json"
+
+type openAPIDocument struct {
+	OpenAPI    string                                     `json:"openapi"`
+	Info       openAPIInfo                                `json:"info"`
+	Paths      map[string]map[string]openAPIPathOperation `json:"paths"`
+	Components openAPIComponents                          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type openAPIPathOperation struct {
+	Summary     string                     `json:"summary,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool                      `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+}
+
+type openAPIOperation struct {
+	summary     string
+	tag         string
+	auth        bool
+	deprecated  bool
+	query       []string
+	request     any
+	reply       any
+	contentType string
+}
+
+var ginPathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+func (api *Api) initOpenAPI() {
+	err := checkOpenAPIDrift(api.router.Routes(), openAPIOperations)
+	if err != nil {
+		api.log.Warn("Routes and OpenAPI specification are out of sync", zap.Error(err))
+	}
+	spec, err := api.generateOpenAPI()
+	if err != nil {
+		api.log.Fatal("Error generating OpenAPI specification", zap.Error(err))
+	}
+	api.openAPISpec = spec
+	api.log.Info("OpenAPI specification generated", zap.Int("bytes", len(spec)))
+}
+
+func (api *Api) getOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", api.openAPISpec)
+}
+
+func (api *Api) generateOpenAPI() ([]byte, error) {
+	generator := &openAPIGenerator{
+		schemas: make(map[string]*openAPISchema),
+		names:   make(map[reflect.Type]string),
+	}
+	document := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   "scalecloud.de API",
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]map[string]openAPIPathOperation),
+		Components: openAPIComponents{
+			Schemas: generator.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"firebase": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	for _, route := range api.router.Routes() {
+		operation := openAPIOperations[route.Method+" "+route.Path]
+		path := ginPathParam.ReplaceAllString(route.Path, "{$1}")
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]openAPIPathOperation)
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = generator.pathOperation(route.Path, operation)
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func checkOpenAPIDrift(routes gin.RoutesInfo, operations map[string]openAPIOperation) error {
+	var missing []string
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := operations[key]; !ok {
+			missing = append(missing, "route without OpenAPI operation: "+key)
+		}
+	}
+	for key := range operations {
+		if !registered[key] {
+			missing = append(missing, "OpenAPI operation without route: "+key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.New(strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+type openAPIGenerator struct {
+	schemas map[string]*openAPISchema
+	names   map[reflect.Type]string
+}
+
+func (generator *openAPIGenerator) pathOperation(path string, operation openAPIOperation) openAPIPathOperation {
+	pathOperation := openAPIPathOperation{
+		Summary:    operation.summary,
+		Deprecated: operation.deprecated,
+		Responses:  make(map[string]openAPIResponse),
+	}
+	if operation.tag != "" {
+		pathOperation.Tags = []string{operation.tag}
+	}
+	for _, match := range ginPathParam.FindAllStringSubmatch(path, -1) {
+		pathOperation.Parameters = append(pathOperation.Parameters, openAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &openAPISchema{Type: "string"},
+		})
+	}
+	for _, name := range operation.query {
+		pathOperation.Parameters = append(pathOperation.Parameters, openAPIParameter{
+			Name:   name,
+			In:     "query",
+			Schema: &openAPISchema{Type: "string"},
+		})
+	}
+	if operation.request != nil {
+		pathOperation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]openAPIMediaType{
+				"application/json": {Schema: generator.schemaFor(reflect.TypeOf(operation.request))},
+			},
+		}
+	}
+	success := openAPIResponse{Description: "Success"}
+	if operation.reply != nil {
+		contentType := operation.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success.Content = map[string]openAPIMediaType{
+			contentType: {Schema: generator.schemaFor(reflect.TypeOf(operation.reply))},
+		}
+	}
+	pathOperation.Responses[strconv.Itoa(http.StatusOK)] = success
+	pathOperation.Responses["default"] = openAPIResponse{
+		Description: "Error",
+		Content: map[string]openAPIMediaType{
+			"application/json": {Schema: generator.schemaFor(reflect.TypeOf(ErrorReply{}))},
+		},
+	}
+	if operation.auth {
+		pathOperation.Security = []map[string][]string{{"firebase": {}}}
+	}
+	return pathOperation
+}
+
+type ErrorReply struct {
+	Error     string `json:"error" validate:"required"`
+	RequestID string `json:"requestID"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (generator *openAPIGenerator) schemaFor(t reflect.Type) *openAPISchema {
+	switch {
+	case t == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := generator.schemaFor(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case t.Kind() == reflect.Struct:
+		return &openAPISchema{Ref: "#/components/schemas/" + generator.structSchema(t)}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &openAPISchema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &openAPISchema{Type: "array", Items: generator.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: generator.schemaFor(t.Elem())}
+	case t.Kind() == reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: integerFormat(t)}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case t.Kind() == reflect.String:
+		return &openAPISchema{Type: "string"}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+func integerFormat(t reflect.Type) string {
+	if t.Bits() == 64 {
+		return "int64"
+	}
+	return "int32"
+}
+
+func (generator *openAPIGenerator) structSchema(t reflect.Type) string {
+	if name, ok := generator.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := generator.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + t.Name()
+	}
+	schema := &openAPISchema{
+		Type:       "object",
+		Properties: make(map[string]*openAPISchema),
+	}
+	generator.names[t] = name
+	generator.schemas[name] = schema
+	generator.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return name
+}
+
+func (generator *openAPIGenerator) addFields(schema *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		jsonName, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+		if field.Anonymous && jsonName == "" && field.Type.Kind() == reflect.Struct {
+			generator.addFields(schema, field.Type)
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		fieldSchema := generator.schemaFor(field.Type)
+		required := applyValidation(fieldSchema, field)
+		if fieldSchema.Ref != "" {
+			fieldSchema = &openAPISchema{Ref: fieldSchema.Ref}
+		}
+		schema.Properties[jsonName] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, jsonName)
+		}
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+func applyValidation(schema *openAPISchema, field reflect.StructField) bool {
+	required := strings.Contains(field.Tag.Get("binding"), "required")
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "oneof":
+			schema.Enum = strings.Fields(value)
+		case "gte", "gt", "lte", "lt", "min", "max":
+			applyBound(schema, name, value)
+		}
+	}
+	return required
+}
+
+func applyBound(schema *openAPISchema, name, value string) {
+	bound, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	isLower := name == "gte" || name == "gt" || name == "min"
+	switch schema.Type {
+	case "string":
+		length := int(bound)
+		if isLower {
+			schema.MinLength = &length
+		} else {
+			schema.MaxLength = &length
+		}
+	case "array":
+		length := int(bound)
+		if isLower {
+			schema.MinItems = &length
+		} else {
+			schema.MaxItems = &length
+		}
+	default:
+		if isLower {
+			schema.Minimum = &bound
+			schema.ExclusiveMinimum = name == "gt"
+		} else {
+			schema.Maximum = &bound
+			schema.ExclusiveMaximum = name == "lt"
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package apimanager
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newTestApi() *Api {
+	gin.SetMode(gin.TestMode)
+	api := &Api{router: gin.New(), log: zap.NewNop()}
+	api.initRoutes()
+	return api
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	api := newTestApi()
+	err := checkOpenAPIDrift(api.router.Routes(), openAPIOperations)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	api := newTestApi()
+	spec, err := api.generateOpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec) == 0 {
+		t.Fatal("empty OpenAPI specification")
+	}
+}
@@ -0,0 +1,103 @@
+package apimanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+var errSubscriptionIDMismatch = errors.New("subscription IDs in the request do not match")
+
+type subscriptionScopedRequest interface {
+	ScopedSubscriptionID() string
+}
+
+type subscriptionReference struct {
+	SubscriptionID string `json:"subscriptionID"`
+}
+
+type permissionRequestBody struct {
+	SubscriptionID string                `json:"subscriptionID"`
+	SeatUpdated    subscriptionReference `json:"seatUpdated"`
+	SeatToDelete   subscriptionReference `json:"seatToDelete"`
+}
+
+// requirePermission authorizes routes that carry the subscription in the :id path parameter.
+func (api *Api) requirePermission(permission mongomanager.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenDetails, err := api.handleTokenDetails(c)
+		if err != nil {
+			c.Abort()
+			return
+		}
+		subscriptionID := c.Param("id")
+		if subscriptionID == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, "subscriptionID is required"))
+			return
+		}
+		api.authorize(c, tokenDetails, subscriptionID, permission)
+	}
+}
+
+// requireBodyPermission authorizes routes that carry the subscription in the JSON body. The body
+// is bound to the handler's request type T, so the checked ID is the one the handler acts on.
+func requireBodyPermission[T subscriptionScopedRequest](api *Api, permission mongomanager.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenDetails, err := api.handleTokenDetails(c)
+		if err != nil {
+			c.Abort()
+			return
+		}
+		subscriptionID, err := bodySubscriptionID[T](c)
+		if err != nil {
+			api.requestLog(c).Warn("Rejected request body", zap.String("uid", tokenDetails.UID), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+			return
+		}
+		api.authorize(c, tokenDetails, subscriptionID, permission)
+	}
+}
+
+func (api *Api) authorize(c *gin.Context, tokenDetails firebasemanager.TokenDetails, subscriptionID string, permission mongomanager.Permission) {
+	err := api.paymentHandler.MongoConnection.HasPermission(c, tokenDetails, subscriptionID, permission)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(c, err.Error()))
+		return
+	}
+	c.Next()
+}
+
+func bodySubscriptionID[T subscriptionScopedRequest](c *gin.Context) (string, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return "", errors.New("error reading request body")
+	}
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	var request T
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		return "", err
+	}
+	subscriptionID := request.ScopedSubscriptionID()
+	if subscriptionID == "" {
+		return "", errors.New("subscriptionID is required")
+	}
+	var references permissionRequestBody
+	err = json.Unmarshal(body, &references)
+	if err != nil {
+		return "", err
+	}
+	for _, reference := range []string{references.SubscriptionID, references.SeatUpdated.SubscriptionID, references.SeatToDelete.SubscriptionID} {
+		if reference != "" && reference != subscriptionID {
+			return "", errSubscriptionIDMismatch
+		}
+	}
+	return subscriptionID, nil
+}
@@ -0,0 +1,165 @@
+package apimanager
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/stripemanager"
+	"go.uber.org/zap"
+)
+
+func newPermissionTestRouter(middleware gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/test", func(c *gin.Context) {
+		c.Set(contextKeyTokenDetails, firebasemanager.TokenDetails{UID: "uid", EMail: "user@example.com"})
+		c.Next()
+	}, middleware, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRequireBodyPermissionRejectsBody(t *testing.T) {
+	api := &Api{log: zap.NewNop()}
+	tests := []struct {
+		name       string
+		middleware gin.HandlerFunc
+		body       string
+		wantError  string
+	}{
+		{
+			name:       "missing subscription ID",
+			middleware: requireBodyPermission[stripemanager.ListSeatRequest](api, mongomanager.PermissionSeatsRead),
+			body:       `{"pageSize": 10}`,
+			wantError:  "subscriptionID is required",
+		},
+		{
+			name:       "invalid JSON",
+			middleware: requireBodyPermission[stripemanager.ListSeatRequest](api, mongomanager.PermissionSeatsRead),
+			body:       `{"subscriptionID":`,
+		},
+		{
+			name:       "seat of another subscription",
+			middleware: requireBodyPermission[stripemanager.UpdateSeatDetailRequest](api, mongomanager.PermissionSeatsWrite),
+			body:       `{"subscriptionID": "sub_a", "seatUpdated": {"subscriptionID": "sub_b", "uid": "uid", "roles": ["User"]}}`,
+			wantError:  errSubscriptionIDMismatch.Error(),
+		},
+		{
+			name:       "deleted seat of another subscription",
+			middleware: requireBodyPermission[stripemanager.DeleteSeatRequest](api, mongomanager.PermissionSeatsWrite),
+			body:       `{"subscriptionID": "sub_a", "seatToDelete": {"subscriptionID": "sub_b", "uid": "uid"}}`,
+			wantError:  errSubscriptionIDMismatch.Error(),
+		},
+		{
+			name:       "seat reference without the scoped subscription",
+			middleware: requireBodyPermission[stripemanager.ListSeatRequest](api, mongomanager.PermissionSeatsRead),
+			body:       `{"subscriptionID": "sub_a", "seatToDelete": {"subscriptionID": "sub_b"}}`,
+			wantError:  errSubscriptionIDMismatch.Error(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := newPermissionTestRouter(test.middleware)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(test.body)))
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+			if test.wantError == "" {
+				return
+			}
+			var reply struct {
+				Error string `json:"error"`
+			}
+			err := json.Unmarshal(recorder.Body.Bytes(), &reply)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reply.Error != test.wantError {
+				t.Errorf("error = %q, want %q", reply.Error, test.wantError)
+			}
+		})
+	}
+}
+
+func TestBodySubscriptionID(t *testing.T) {
+	tests := []struct {
+		name    string
+		scoped  func(*gin.Context) (string, error)
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "top level subscription ID",
+			scoped: bodySubscriptionID[stripemanager.ListSeatRequest],
+			body:   `{"subscriptionID": "sub_a"}`,
+			want:   "sub_a",
+		},
+		{
+			name:   "updated seat",
+			scoped: bodySubscriptionID[stripemanager.UpdateSeatDetailRequest],
+			body:   `{"seatUpdated": {"subscriptionID": "sub_a", "uid": "uid"}}`,
+			want:   "sub_a",
+		},
+		{
+			name:   "updated seat with matching top level ID",
+			scoped: bodySubscriptionID[stripemanager.UpdateSeatDetailRequest],
+			body:   `{"subscriptionID": "sub_a", "seatUpdated": {"subscriptionID": "sub_a", "uid": "uid"}}`,
+			want:   "sub_a",
+		},
+		{
+			name:   "deleted seat",
+			scoped: bodySubscriptionID[stripemanager.DeleteSeatRequest],
+			body:   `{"seatToDelete": {"subscriptionID": "sub_a", "uid": "uid"}}`,
+			want:   "sub_a",
+		},
+		{
+			name:    "top level ID ignored by the handler",
+			scoped:  bodySubscriptionID[stripemanager.DeleteSeatRequest],
+			body:    `{"subscriptionID": "sub_a"}`,
+			wantErr: true,
+		},
+		{
+			name:    "mismatched top level ID",
+			scoped:  bodySubscriptionID[stripemanager.DeleteSeatRequest],
+			body:    `{"subscriptionID": "sub_a", "seatToDelete": {"subscriptionID": "sub_b"}}`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(test.body))
+			got, err := test.scoped(c)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("bodySubscriptionID() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("bodySubscriptionID() = %q, want %q", got, test.want)
+			}
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.body {
+				t.Errorf("body was not restored for the handler: %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package apimanager
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/ratelimitmanager"
+	"go.uber.org/zap"
+)
+
+func (api *Api) ipRateLimit(group ratelimitmanager.Group) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		api.checkRateLimit(c, group, "ip:"+c.ClientIP())
+	}
+}
+
+func (api *Api) uidRateLimit(group ratelimitmanager.Group) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenDetails, err := api.handleTokenDetails(c)
+		if err != nil {
+			c.Abort()
+			return
+		}
+		api.checkRateLimit(c, group, "uid:"+tokenDetails.UID)
+	}
+}
+
+func (api *Api) checkRateLimit(c *gin.Context, group ratelimitmanager.Group, key string) {
+	allowed, retryAfter := api.rateLimiter.Allow(c, group, key)
+	if !allowed {
+		api.requestLog(c).Warn("Rate limit exceeded", zap.String("group", string(group)), zap.String("key", key), zap.Duration("retryAfter", retryAfter))
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse(c, "too many requests"))
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,41 @@
+package apimanager
+
+import (
+	"net/http"
+
+	sentrygin "github.com/getsentry/sentry-go/gin"
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
+	"go.uber.org/zap"
+)
+
+func (api *Api) requestIDRequired(c *gin.Context) {
+	requestID := c.Request.Header.Get(requestmanager.HeaderRequestID)
+	if !requestmanager.IsValidRequestID(requestID) {
+		generatedID, err := requestmanager.GenerateRequestID()
+		if err != nil {
+			api.log.Error("Error generating request ID", zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		requestID = generatedID
+	}
+	c.Set(requestmanager.ContextKeyRequestID, requestID)
+	c.Set(requestmanager.ContextKeyClientIP, c.ClientIP())
+	c.Header(requestmanager.HeaderRequestID, requestID)
+	if hub := sentrygin.GetHubFromContext(c); hub != nil {
+		hub.Scope().SetTag("request_id", requestID)
+	}
+	c.Next()
+}
+
+func (api *Api) requestLog(c *gin.Context) *zap.Logger {
+	return requestmanager.GetLogger(c, api.log)
+}
+
+func errorResponse(c *gin.Context, message string) gin.H {
+	return gin.H{
+		"error":     message,
+		"requestID": requestmanager.GetRequestID(c),
+	}
+}
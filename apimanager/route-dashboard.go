@@ -88,7 +88,8 @@ func (api *Api) getSubscriptionUpdateSeat(c *gin.Context) {
 	var request stripemanager.UpdateSeatDetailRequest
 	tokenDetails, err := api.handleTokenDetails(c)
 	if err == nil &&
-		api.handleBind(c, &request) {
+		api.handleBind(c, &request) &&
+		api.validateStruct(c, request) {
 		reply, err := api.paymentHandler.GetSubscriptionUpdateSeat(c, tokenDetails, request)
 		api.validateAndWriteReply(c, err, reply)
 	}
@@ -98,7 +99,8 @@ func (api *Api) getSubscriptionAddSeat(c *gin.Context) {
 	var request stripemanager.AddSeatRequest
 	tokenDetails, err := api.handleTokenDetails(c)
 	if err == nil &&
-		api.handleBind(c, &request) {
+		api.handleBind(c, &request) &&
+		api.validateStruct(c, request) {
 		reply, err := api.paymentHandler.GetSubscriptionAddSeat(c, tokenDetails, request)
 		api.validateAndWriteReply(c, err, reply)
 	}
@@ -0,0 +1,495 @@
+package apimanager
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/stripemanager"
+	"go.uber.org/zap"
+)
+
+const maxImportBodyBytes = 1 << 20
+
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
+
+func (api *Api) handleQueryInt(c *gin.Context, name string, defaultValue int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return defaultValue, true
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		api.requestLog(c).Warn("Invalid query parameter", zap.String("name", name), zap.Error(err))
+		c.SecureJSON(http.StatusBadRequest, errorResponse(c, "invalid query parameter "+name))
+		return 0, false
+	}
+	return i, true
+}
+
+func (api *Api) v1GetProductTiers(c *gin.Context) {
+	var prodType stripemanager.ProductType
+	switch strings.ToLower(c.Param("productType")) {
+	case strings.ToLower(string(stripemanager.ProductNextcloud)):
+		prodType = stripemanager.ProductNextcloud
+	case strings.ToLower(string(stripemanager.ProductSynology)):
+		prodType = stripemanager.ProductSynology
+	default:
+		c.SecureJSON(http.StatusNotFound, errorResponse(c, "unknown product type"))
+		return
+	}
+	reply, err := api.paymentHandler.GetProductTiers(c, prodType)
+	api.validateAndWriteReply(c, err, reply)
+}
+
+func (api *Api) v1GetMyPermission(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.PermissionRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.GetMyPermission(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1GetMyPermissions(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.PermissionRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.GetMyPermissions(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ListSeats(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	pageIndex, ok := api.handleQueryInt(c, "pageIndex", 0)
+	if !ok {
+		return
+	}
+	pageSize, ok := api.handleQueryInt(c, "pageSize", 10)
+	if !ok {
+		return
+	}
+	request := stripemanager.ListSeatRequest{
+		SubscriptionID: c.Param("id"),
+		PageIndex:      pageIndex,
+		PageSize:       pageSize,
+		Search:         c.Query("search"),
+		Role:           mongomanager.Role(c.Query("role")),
+		SortBy:         mongomanager.SeatSortField(c.Query("sortBy")),
+		SortDirection:  c.Query("sortDirection"),
+		Cursor:         c.Query("cursor"),
+	}
+	if value := c.Query("emailVerified"); value != "" {
+		emailVerified, err := strconv.ParseBool(value)
+		if err != nil {
+			api.requestLog(c).Warn("Invalid query parameter", zap.String("name", "emailVerified"), zap.Error(err))
+			c.SecureJSON(http.StatusBadRequest, errorResponse(c, "invalid query parameter emailVerified"))
+			return
+		}
+		request.EMailVerified = &emailVerified
+	}
+	if api.validateStruct(c, request) {
+		reply, err := api.paymentHandler.GetSubscriptionListSeats(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ExportSeats(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	request := stripemanager.ExportSeatsRequest{
+		SubscriptionID: c.Param("id"),
+		Format:         stripemanager.ExportFormat(c.DefaultQuery("format", string(stripemanager.ExportFormatCSV))),
+	}
+	api.writeSeatExport(c, tokenDetails, request)
+}
+
+func (api *Api) writeSeatExport(c *gin.Context, tokenDetails firebasemanager.TokenDetails, request stripemanager.ExportSeatsRequest) {
+	if !api.validateStruct(c, request) {
+		return
+	}
+	export, err := api.paymentHandler.ExportSeats(c, tokenDetails, request)
+	if !api.handleReplyError(c, err) {
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if request.Format == stripemanager.ExportFormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=\"seats-"+request.SubscriptionID+"."+string(request.Format)+"\"")
+	c.Status(http.StatusOK)
+	err = export(c.Writer)
+	if err != nil {
+		api.requestLog(c).Error("Error exporting seats", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+	}
+}
+
+func (api *Api) v1AddSeat(c *gin.Context) {
+	var request stripemanager.AddSeatRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		if api.validateStruct(c, request) {
+			reply, err := api.paymentHandler.GetSubscriptionAddSeat(c, tokenDetails, request)
+			api.validateAndWriteReply(c, err, reply)
+		}
+	}
+}
+
+func (api *Api) v1DeleteSeats(c *gin.Context) {
+	var request stripemanager.DeleteSeatsRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		if api.validateStruct(c, request) {
+			reply, err := api.paymentHandler.DeleteSeats(c, tokenDetails, request)
+			api.validateAndWriteReply(c, err, reply)
+		}
+	}
+}
+
+func (api *Api) v1GetSeat(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.SeatDetailRequest{
+			SubscriptionID: c.Param("id"),
+			UID:            c.Param("uid"),
+		}
+		reply, err := api.paymentHandler.GetSubscriptionSeatDetail(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1UpdateSeat(c *gin.Context) {
+	var request stripemanager.SeatRolesUpdate
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		request.UID = c.Param("uid")
+		if api.validateStruct(c, request) {
+			reply, err := api.paymentHandler.GetSubscriptionUpdateSeat(c, tokenDetails, stripemanager.UpdateSeatDetailRequest{SeatUpdated: request})
+			api.validateAndWriteReply(c, err, reply)
+		}
+	}
+}
+
+func (api *Api) v1UpdateSeatRoles(c *gin.Context) {
+	var request stripemanager.UpdateSeatRolesRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		request.UID = c.Param("uid")
+		if api.validateStruct(c, request) {
+			reply, err := api.paymentHandler.UpdateSeatRoles(c, tokenDetails, request)
+			api.validateAndWriteReply(c, err, reply)
+		}
+	}
+}
+
+func (api *Api) v1DeleteSeat(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.DeleteSeatRequest{
+			SeatToDelete: mongomanager.Seat{
+				SubscriptionID: c.Param("id"),
+				UID:            c.Param("uid"),
+			},
+		}
+		reply, err := api.paymentHandler.GetSubscriptionRemoveSeat(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ListInvoices(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	pageSize, ok := api.handleQueryInt(c, "pageSize", 10)
+	if !ok {
+		return
+	}
+	request := stripemanager.ListInvoicesRequest{
+		SubscriptionID: c.Param("id"),
+		PageSize:       pageSize,
+		StartingAfter:  c.Query("startingAfter"),
+		EndingBefore:   c.Query("endingBefore"),
+	}
+	if api.validateStruct(c, request) {
+		reply, err := api.paymentHandler.GetSubscriptionInvoices(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1GetUpcomingInvoice(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.UpcomingInvoiceRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.GetUpcomingInvoice(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1GetInvoicePDF(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	request := stripemanager.InvoicePDFRequest{
+		SubscriptionID: c.Param("id"),
+		InvoiceID:      c.Param("invoiceID"),
+	}
+	if !api.validateStruct(c, request) {
+		return
+	}
+	body, fileName, err := api.paymentHandler.GetInvoicePDF(c, tokenDetails, request)
+	if !api.handleReplyError(c, err) {
+		return
+	}
+	defer body.Close()
+	c.DataFromReader(http.StatusOK, -1, "application/pdf", body, map[string]string{
+		"Content-Disposition": "attachment; filename=\"" + fileName + "\"",
+	})
+}
+
+func (api *Api) v1GetInvoiceArchive(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	year, ok := api.handleQueryInt(c, "year", time.Now().Year())
+	if !ok {
+		return
+	}
+	request := stripemanager.InvoiceArchiveRequest{
+		SubscriptionID: c.Param("id"),
+		Year:           year,
+	}
+	if !api.validateStruct(c, request) {
+		return
+	}
+	archive, err := api.paymentHandler.GetInvoiceArchive(c, tokenDetails, request)
+	if !api.handleReplyError(c, err) {
+		return
+	}
+	defer archive.Close()
+	c.DataFromReader(http.StatusOK, archive.Size, "application/zip", archive, map[string]string{
+		"Content-Disposition": "attachment; filename=\"invoices-" + request.SubscriptionID + "-" + strconv.Itoa(request.Year) + ".zip\"",
+	})
+}
+
+func (api *Api) v1GetBillingAddress(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.BillingAddressRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.GetBillingAddress(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1UpdateBillingAddress(c *gin.Context) {
+	var request stripemanager.UpdateBillingAddressRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		reply, err := api.paymentHandler.UpdateBillingAddress(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1CancelSubscription(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.SubscriptionCancelRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.CancelSubscription(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ResumeSubscription(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.SubscriptionResumeRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.ResumeSubscription(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1GetCheckoutProduct(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.CheckoutProductRequest{
+			ProductID:     c.Param("id"),
+			PromotionCode: c.Query("promotionCode"),
+		}
+		reply, err := api.paymentHandler.GetCheckoutProduct(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1GetCheckoutBillingAddress(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		reply, err := api.paymentHandler.GetCheckoutBillingAddress(c, tokenDetails)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1UpdateCheckoutBillingAddress(c *gin.Context) {
+	var request stripemanager.UpdateCheckoutBillingAddressRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		reply, err := api.paymentHandler.UpdateCheckoutBillingAddress(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ImportSeats(c *gin.Context) {
+	var request stripemanager.ImportSeatsRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	switch c.ContentType() {
+	case "text/csv":
+		rows, err := stripemanager.ParseImportSeatsCSV(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes))
+		if err != nil {
+			api.requestLog(c).Warn("Error parsing seat import CSV", zap.Error(err))
+			c.SecureJSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+			return
+		}
+		request.Seats = rows
+	case gin.MIMEJSON:
+		if !api.handleBind(c, &request) {
+			return
+		}
+	default:
+		c.SecureJSON(http.StatusUnsupportedMediaType, errorResponse(c, "Content-Type must be text/csv or application/json"))
+		return
+	}
+	request.SubscriptionID = c.Param("id")
+	reply, err := api.paymentHandler.ImportSeats(c, tokenDetails, request)
+	api.validateAndWriteReply(c, err, reply)
+}
+
+func (api *Api) v1GetOwnerTransfer(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.PendingOwnerTransferRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.GetPendingOwnerTransfer(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1RequestOwnerTransfer(c *gin.Context) {
+	var request stripemanager.OwnerTransferRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) {
+		request.SubscriptionID = c.Param("id")
+		reply, err := api.paymentHandler.RequestOwnerTransfer(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1CancelOwnerTransfer(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.PendingOwnerTransferRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.CancelOwnerTransfer(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1AcceptOwnerTransfer(c *gin.Context) {
+	var request stripemanager.OwnerTransferTokenRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) &&
+		api.validateStruct(c, request) {
+		reply, err := api.paymentHandler.AcceptOwnerTransfer(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1DeclineOwnerTransfer(c *gin.Context) {
+	var request stripemanager.OwnerTransferTokenRequest
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil &&
+		api.handleBind(c, &request) &&
+		api.validateStruct(c, request) {
+		reply, err := api.paymentHandler.DeclineOwnerTransfer(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1ListAuditLog(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err != nil {
+		return
+	}
+	pageSize, ok := api.handleQueryInt(c, "pageSize", 25)
+	if !ok {
+		return
+	}
+	request := stripemanager.AuditLogRequest{
+		SubscriptionID: c.Param("id"),
+		PageSize:       pageSize,
+		Action:         mongomanager.AuditAction(c.Query("action")),
+		Cursor:         c.Query("cursor"),
+	}
+	if api.validateStruct(c, request) {
+		reply, err := api.paymentHandler.ListAuditLog(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
+
+func (api *Api) v1LeaveSubscription(c *gin.Context) {
+	tokenDetails, err := api.handleTokenDetails(c)
+	if err == nil {
+		request := stripemanager.LeaveSubscriptionRequest{
+			SubscriptionID: c.Param("id"),
+		}
+		reply, err := api.paymentHandler.LeaveSubscription(c, tokenDetails, request)
+		api.validateAndWriteReply(c, err, reply)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
 	"github.com/scalecloud/scalecloud.de-api/stripemanager"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/subscription"
@@ -16,22 +17,33 @@ import (
 )
 
 func (api *Api) StripeRequired(c *gin.Context) {
-	isPost(c, api.log)
+	isPost(c, api.webhookLog(c))
 
 	token, hasAuth := getStripeToken(c)
 	if hasAuth && token != "" {
-		api.log.Debug("Has Stripe Signature", zap.String("token:", token))
+		api.webhookLog(c).Debug("Has Stripe Signature", zap.String("token:", token))
 		c.Next()
 	} else {
-		api.log.Warn("Unauthorized")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		api.webhookLog(c).Warn("Unauthorized")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(c, "unauthorized"))
 	}
 }
 
 func isPost(c *gin.Context, log *zap.Logger) {
 	if c.Request.Method != http.MethodPost {
 		log.Warn("Method not allowed", zap.String("Method", c.Request.Method))
-		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, errorResponse(c, "Method not allowed"))
+	}
+}
+
+func (api *Api) webhookLog(c context.Context) *zap.Logger {
+	return requestmanager.GetLogger(c, api.webhookHandler.Log)
+}
+
+func webhookResponse(c *gin.Context, message string) gin.H {
+	return gin.H{
+		"message":   message,
+		"requestID": requestmanager.GetRequestID(c),
 	}
 }
 
@@ -47,55 +59,73 @@ func getStripeToken(c *gin.Context) (string, bool) {
 func (api *Api) handleStripeWebhook(c *gin.Context) {
 	var endpointSecret = api.webhookHandler.StripeConnection.EndpointSecret
 	if endpointSecret == "" {
-		api.log.Error("Missing endpoint secret")
-		c.SecureJSON(http.StatusServiceUnavailable, gin.H{"message": "Service unavailable"})
+		api.webhookLog(c).Error("Missing endpoint secret")
+		c.SecureJSON(http.StatusServiceUnavailable, webhookResponse(c, "Service unavailable"))
 	}
 	payload, err := c.GetRawData()
 	if err != nil {
-		api.log.Error("Error getting raw data", zap.Error(err))
-		c.SecureJSON(http.StatusNoContent, gin.H{"message": "Error getting raw data"})
+		api.webhookLog(c).Error("Error getting raw data", zap.Error(err))
+		c.SecureJSON(http.StatusNoContent, webhookResponse(c, "Error getting raw data"))
 	}
 	event, err := webhook.ConstructEvent(payload, c.Request.Header.Get("Stripe-Signature"), endpointSecret)
 	if err != nil {
-		api.log.Error("Signature verification failed", zap.Error(err))
-		c.SecureJSON(http.StatusUnauthorized, gin.H{"message": "Signature verification failed"})
+		api.webhookLog(c).Error("Signature verification failed", zap.Error(err))
+		c.SecureJSON(http.StatusUnauthorized, webhookResponse(c, "Signature verification failed"))
 	}
 	switch event.Type {
 	case "payment_method.attached":
 		err := api.handlePaymentMethodAttached(c, event)
 		if err != nil {
-			api.log.Error("Error handling payment_method.attached", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"message": err})
+			api.webhookLog(c).Error("Error handling payment_method.attached", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
 		}
 	case "setup_intent.created":
 		err := api.handleSetupIntentCreated(c, event)
 		if err != nil {
-			api.log.Error("Error handling setup_intent.created", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"message": err})
+			api.webhookLog(c).Error("Error handling setup_intent.created", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
 		}
 	case "setup_intent.succeeded":
 		err := api.handleSetupIntentSucceeded(c, event)
 		if err != nil {
-			api.log.Error("Error handling setup_intent.succeeded", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"message": err})
+			api.webhookLog(c).Error("Error handling setup_intent.succeeded", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
 		}
 	case "customer.subscription.created":
 		err := api.handleCustomerSubscriptionCreated(c, event)
 		if err != nil {
-			api.log.Error("Error handling customer.subscription.created", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"message": err})
+			api.webhookLog(c).Error("Error handling customer.subscription.created", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
 		}
 	case "customer.subscription.deleted":
 		err := api.handleCustomerSubscriptionDeleted(c, event)
 		if err != nil {
-			api.log.Error("Error handling customer.subscription.deleted", zap.Error(err))
-			c.SecureJSON(http.StatusInternalServerError, gin.H{"message": err})
+			api.webhookLog(c).Error("Error handling customer.subscription.deleted", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
+		}
+	case "invoice.created":
+		err := api.handleInvoiceCountChanged(c, event, api.paymentHandler.InvoiceCreated)
+		if err != nil {
+			api.webhookLog(c).Error("Error handling invoice.created", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
+		}
+	case "invoice.deleted":
+		err := api.handleInvoiceCountChanged(c, event, api.paymentHandler.InvoiceDeleted)
+		if err != nil {
+			api.webhookLog(c).Error("Error handling invoice.deleted", zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
+		}
+	case "customer.tax_id.updated", "customer.tax_id.deleted":
+		err := api.handleTaxIDChanged(c, event)
+		if err != nil {
+			api.webhookLog(c).Error("Error handling "+string(event.Type), zap.Error(err))
+			c.SecureJSON(http.StatusInternalServerError, webhookResponse(c, err.Error()))
 		}
 	default:
-		api.log.Warn("Unhandled event type", zap.Any("Unhandled event type", event.Type))
-		c.SecureJSON(http.StatusNotImplemented, gin.H{"message": "Unhandled event type"})
+		api.webhookLog(c).Warn("Unhandled event type", zap.Any("Unhandled event type", event.Type))
+		c.SecureJSON(http.StatusNotImplemented, webhookResponse(c, "Unhandled event type"))
 	}
-	api.log.Info("Handled webhook", zap.Any("Handled webhook", event.Type))
+	api.webhookLog(c).Info("Handled webhook", zap.Any("Handled webhook", event.Type))
 }
 
 func (api *Api) handlePaymentMethodAttached(c context.Context, event stripe.Event) error {
@@ -108,7 +138,7 @@ func (api *Api) handlePaymentMethodAttached(c context.Context, event stripe.Even
 	if err != nil {
 		return err
 	}
-	api.log.Debug("paymentMethod was updated", zap.Any("paymentMethodID", request.ID))
+	api.webhookLog(c).Debug("paymentMethod was updated", zap.Any("paymentMethodID", request.ID))
 	return nil
 }
 
@@ -122,7 +152,7 @@ func (api *Api) handleSetupIntentCreated(c context.Context, event stripe.Event)
 	if err != nil {
 		return err
 	}
-	api.log.Debug("SetupIntentCreated", zap.Any("setupIntentID", request.ID))
+	api.webhookLog(c).Debug("SetupIntentCreated", zap.Any("setupIntentID", request.ID))
 	return nil
 }
 
@@ -143,7 +173,7 @@ func (api *Api) handleSetupIntentSucceeded(c context.Context, event stripe.Event
 	if cus.ID == "" {
 		return errors.New("Customer ID not set")
 	}
-	api.log.Debug("Customer", zap.Any("Customer", cus.ID))
+	api.webhookLog(c).Debug("Customer", zap.Any("Customer", cus.ID))
 
 	meta := request.Metadata
 	if meta == nil {
@@ -154,7 +184,7 @@ func (api *Api) handleSetupIntentSucceeded(c context.Context, event stripe.Event
 		return errors.New("Metadata type not set")
 	}
 	if metaKey == string(stripemanager.CreateSubscription) {
-		api.log.Info("createSubscription")
+		api.webhookLog(c).Info("createSubscription")
 	} else if metaKey == string(stripemanager.ChangePayment) {
 		api.paymentHandler.ChangePaymentDefault(c, request)
 		api.paymentHandler.ChangeCustomerAddress(c, request)
@@ -180,6 +210,24 @@ func (api *Api) handleCustomerSubscriptionDeleted(c context.Context, event strip
 	return nil
 }
 
+func (api *Api) handleInvoiceCountChanged(c context.Context, event stripe.Event, apply func(context.Context, stripe.Invoice) error) error {
+	var inv stripe.Invoice
+	err := json.Unmarshal(event.Data.Raw, &inv)
+	if err != nil {
+		return err
+	}
+	return apply(c, inv)
+}
+
+func (api *Api) handleTaxIDChanged(c context.Context, event stripe.Event) error {
+	var taxID stripe.TaxID
+	err := json.Unmarshal(event.Data.Raw, &taxID)
+	if err != nil {
+		return err
+	}
+	return api.paymentHandler.TaxIDChanged(c, taxID)
+}
+
 func (api *Api) handleAddingTrialUsed(c context.Context, event stripe.Event) error {
 	var sub stripe.Subscription
 	err := json.Unmarshal(event.Data.Raw, &sub)
@@ -192,7 +240,7 @@ func (api *Api) handleAddingTrialUsed(c context.Context, event stripe.Event) err
 	}
 	status := sub.Status
 	if status != stripe.SubscriptionStatusTrialing {
-		api.log.Info("Subscription status is not trialing, no need for action.", zap.Any("status", status))
+		api.webhookLog(c).Info("Subscription status is not trialing, no need for action.", zap.Any("status", status))
 		return nil
 	}
 	quantity := sub.Items.Data[0].Quantity
@@ -267,21 +315,25 @@ func (api *Api) handleSubscriptionEnded(c context.Context, event stripe.Event) e
 	}
 	status := sub.Status
 	if status != stripe.SubscriptionStatusCanceled {
-		api.log.Warn("Subscription is not canceled but customer.subscription.deleted was called.", zap.Any("SubscriptionID", sub.ID))
+		api.webhookLog(c).Warn("Subscription is not canceled but customer.subscription.deleted was called.", zap.Any("SubscriptionID", sub.ID))
 		return errors.New("Subscription is not canceled but customer.subscription.deleted was called. SubscriptionID: " + sub.ID)
 	}
-	err = api.removeStripeUser(c, sub.Customer.ID)
+	removeUser, err := api.hasOnlyCanceledSubscriptions(c, sub.Customer.ID)
 	if err != nil {
 		return err
 	}
-	err = api.removeSubscriptionSeats(c, sub.ID)
-	if err != nil {
-		return err
-	}
-	return nil
+	return api.paymentHandler.MongoConnection.WithTransaction(c, func(ctx context.Context) error {
+		if removeUser {
+			err := api.removeStripeUser(ctx, sub.Customer.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return api.removeSubscriptionSeats(ctx, sub.ID)
+	})
 }
 
-func (api *Api) removeStripeUser(c context.Context, customerID string) error {
+func (api *Api) hasOnlyCanceledSubscriptions(c context.Context, customerID string) (bool, error) {
 	stripe.Key = api.paymentHandler.StripeConnection.Key
 	params := &stripe.SubscriptionListParams{
 		Customer: stripe.String(customerID),
@@ -290,29 +342,31 @@ func (api *Api) removeStripeUser(c context.Context, customerID string) error {
 	for iter.Next() {
 		subscription := iter.Subscription()
 		if subscription.Status != stripe.SubscriptionStatusCanceled {
-			api.log.Info("No need to remove user as there is an active subscription", zap.Any("SubscriptionID", subscription.ID))
-			return nil
+			api.webhookLog(c).Info("No need to remove user as there is an active subscription", zap.Any("SubscriptionID", subscription.ID))
+			return false, nil
 		}
 	}
+	err := iter.Err()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (api *Api) removeStripeUser(c context.Context, customerID string) error {
 	err := api.paymentHandler.MongoConnection.DeleteUser(c, customerID)
 	if err != nil {
 		return err
 	}
-	api.log.Info("User removed because all subscriptions are canceled", zap.Any("CustomerID", customerID))
+	api.webhookLog(c).Info("User removed because all subscriptions are canceled", zap.Any("CustomerID", customerID))
 	return nil
 }
 
 func (api *Api) removeSubscriptionSeats(c context.Context, subscriptionID string) error {
-	seats, err := api.paymentHandler.MongoConnection.GetAllSeats(c, subscriptionID)
+	deleted, err := api.paymentHandler.MongoConnection.DeleteSeatsBySubscription(c, subscriptionID)
 	if err != nil {
 		return err
 	}
-	for _, seat := range seats {
-		err = api.paymentHandler.MongoConnection.DeleteSeat(c, seat)
-		if err != nil {
-			return err
-		}
-	}
-	api.log.Info("All seats removed", zap.Any("SubscriptionID", subscriptionID))
+	api.webhookLog(c).Info("Seats removed because subscription ended", zap.String("SubscriptionID", subscriptionID), zap.Int64("seats", deleted))
 	return nil
 }
@@ -0,0 +1,36 @@
+package apimanager
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const seatVerificationSyncInterval = 15 * time.Minute
+
+// startSeatVerificationSync runs the sync until ctx is cancelled at shutdown.
+func (api *Api) startSeatVerificationSync(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(seatVerificationSyncInterval)
+		defer ticker.Stop()
+		for {
+			api.syncSeatVerification(ctx)
+			select {
+			case <-ctx.Done():
+				api.log.Info("Stopped seat email verification sync")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (api *Api) syncSeatVerification(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, seatVerificationSyncInterval)
+	defer cancel()
+	err := api.paymentHandler.SyncUnverifiedSeats(ctx)
+	if err != nil {
+		api.log.Error("Error syncing seat email verification", zap.Error(err))
+	}
+}
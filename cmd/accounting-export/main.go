@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/scalecloud/scalecloud.de-api/stripemanager"
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	log, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer log.Sync()
+
+	request, output := parseFlags(log)
+
+	stripeConnection, err := stripemanager.InitStripeConnection(context.Background(), log)
+	if err != nil {
+		log.Fatal("Error initializing Stripe connection", zap.Error(err))
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			log.Fatal("Error creating output file", zap.String("output", output), zap.Error(err))
+		}
+		defer file.Close()
+		w = file
+	}
+
+	err = stripeConnection.ExportAccounting(context.Background(), request, w)
+	if err != nil {
+		log.Fatal("Error exporting accounting records", zap.Error(err))
+	}
+	log.Info("Accounting export finished.", zap.String("format", string(request.Format)), zap.String("output", output))
+}
+
+func parseFlags(log *zap.Logger) (stripemanager.AccountingExportRequest, string) {
+	var from, to, format, output, timeZone string
+	request := stripemanager.AccountingExportRequest{}
+	flag.StringVar(&from, "from", "", "First day of the export, e.g. 2025-01-01.")
+	flag.StringVar(&to, "to", "", "Last day of the export (inclusive), e.g. 2025-03-31.")
+	flag.StringVar(&format, "format", string(stripemanager.AccountingExportFormatCSV), "Output format: csv or datev.")
+	flag.StringVar(&output, "output", "", "File to write the export to. Defaults to stdout.")
+	flag.StringVar(&timeZone, "timeZone", "Europe/Berlin", "Time zone used for the date range and booking dates.")
+	flag.IntVar(&request.DATEV.ConsultantNumber, "datevConsultant", 0, "DATEV consultant number (Beraternummer).")
+	flag.IntVar(&request.DATEV.ClientNumber, "datevClient", 0, "DATEV client number (Mandantennummer).")
+	flag.StringVar(&request.DATEV.Currency, "datevCurrency", "", "Currency of the DATEV export. Records in other currencies are rejected. Defaults to EUR.")
+	flag.StringVar(&request.DATEV.RevenueAccount, "datevRevenueAccount", "", "DATEV revenue account for 19% VAT. Defaults to 8400.")
+	flag.StringVar(&request.DATEV.ReducedRevenueAccount, "datevReducedRevenueAccount", "", "DATEV revenue account for 7% VAT. Defaults to 8300.")
+	flag.StringVar(&request.DATEV.ReverseChargeAccount, "datevReverseChargeAccount", "", "DATEV revenue account for EU reverse charge. Defaults to 8336.")
+	flag.StringVar(&request.DATEV.TaxFreeAccount, "datevTaxFreeAccount", "", "DATEV revenue account for tax free revenue. Defaults to 8338.")
+	flag.StringVar(&request.DATEV.ForeignRevenueAccount, "datevForeignRevenueAccount", "", "DATEV revenue account for foreign VAT rates. Defaults to 8320.")
+	flag.StringVar(&request.DATEV.ForeignTaxKey, "datevForeignTaxKey", "", "DATEV tax key (BU-Schlüssel) for foreign VAT rates.")
+	flag.StringVar(&request.DATEV.DebtorAccount, "datevDebtorAccount", "", "DATEV debtor account. Defaults to 10000.")
+	flag.StringVar(&request.DATEV.BankAccount, "datevBankAccount", "", "DATEV bank or clearing account. Defaults to 1360.")
+	flag.Parse()
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Fatal("Invalid time zone", zap.String("timeZone", timeZone), zap.Error(err))
+	}
+	request.From, err = time.ParseInLocation(dateLayout, from, location)
+	if err != nil {
+		log.Fatal("Invalid -from date", zap.String("from", from), zap.Error(err))
+	}
+	toDay, err := time.ParseInLocation(dateLayout, to, location)
+	if err != nil {
+		log.Fatal("Invalid -to date", zap.String("to", to), zap.Error(err))
+	}
+	request.To = toDay.AddDate(0, 0, 1)
+	request.Format = stripemanager.AccountingExportFormat(format)
+	return request, output
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/TheZeroSlave/zapsentry"
@@ -52,7 +56,9 @@ func main() {
 		log.Info("Closing MongoDB Client.")
 		api.CloseMongoClient()
 	}()
-	api.RunAPI()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	api.RunAPI(ctx)
 	log.Info("App finished.")
 }
 
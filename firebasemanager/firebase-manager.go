@@ -89,9 +89,12 @@ func (firebaseConnection *FirebaseConnection) GetTokenDetails(c *gin.Context) (t
 	if err != nil {
 		return TokenDetails{}, err
 	}
+	emailVerified, _ := idToken.Claims["email_verified"].(bool)
 	token := TokenDetails{
-		UID:   uid,
-		EMail: email,
+		UID:           uid,
+		EMail:         email,
+		EMailVerified: emailVerified,
+		IssuedAt:      idToken.IssuedAt,
 	}
 	return token, nil
 }
@@ -0,0 +1,34 @@
+package firebasemanager
+
+import (
+	"context"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+const maxGetUsersIdentifiers = 100
+
+// GetEMailVerified reads email_verified from the Firebase user records, batching GetUser lookups
+// through GetUsers, so the result does not depend on the claims of an ID token.
+func (firebaseConnection *FirebaseConnection) GetEMailVerified(ctx context.Context, uids []string) (map[string]bool, error) {
+	client, err := firebaseConnection.firebaseApp.Auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	verified := make(map[string]bool, len(uids))
+	for start := 0; start < len(uids); start += maxGetUsersIdentifiers {
+		end := min(start+maxGetUsersIdentifiers, len(uids))
+		identifiers := make([]auth.UserIdentifier, 0, end-start)
+		for _, uid := range uids[start:end] {
+			identifiers = append(identifiers, auth.UIDIdentifier{UID: uid})
+		}
+		result, err := client.GetUsers(ctx, identifiers)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range result.Users {
+			verified[user.UID] = user.EmailVerified
+		}
+	}
+	return verified, nil
+}
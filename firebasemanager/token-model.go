@@ -1,6 +1,8 @@
 package firebasemanager
 
 type TokenDetails struct {
-	UID   string `json:"uid" validate:"required"`
-	EMail string `json:"email" validate:"required"`
+	UID           string `json:"uid" validate:"required"`
+	EMail         string `json:"email" validate:"required"`
+	EMailVerified bool   `json:"emailVerified"`
+	IssuedAt      int64  `json:"-"`
 }
@@ -0,0 +1,48 @@
+package mongomanager
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditAction string
+
+const (
+	AuditActionSeatAdd              AuditAction = "seat.add"
+	AuditActionSeatUpdate           AuditAction = "seat.update"
+	AuditActionSeatRemove           AuditAction = "seat.remove"
+	AuditActionSeatImport           AuditAction = "seat.import"
+	AuditActionSeatLeave            AuditAction = "seat.leave"
+	AuditActionSubscriptionCreate   AuditAction = "subscription.create"
+	AuditActionSubscriptionCancel   AuditAction = "subscription.cancel"
+	AuditActionSubscriptionResume   AuditAction = "subscription.resume"
+	AuditActionBillingAddressUpdate AuditAction = "billingAddress.update"
+	AuditActionPaymentMethodChange  AuditAction = "paymentMethod.change"
+	AuditActionOwnerTransferRequest AuditAction = "ownerTransfer.request"
+	AuditActionOwnerTransferCancel  AuditAction = "ownerTransfer.cancel"
+	AuditActionOwnerTransferDecline AuditAction = "ownerTransfer.decline"
+	AuditActionOwnerTransferAccept  AuditAction = "ownerTransfer.accept"
+)
+
+type AuditEntry struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SubscriptionID string             `bson:"subscriptionID" json:"subscriptionID" validate:"required"`
+	ActorUID       string             `bson:"actorUID" json:"actorUID" validate:"required"`
+	ActorEMail     string             `bson:"actorEMail,omitempty" json:"actorEMail,omitempty"`
+	Action         AuditAction        `bson:"action" json:"action" validate:"required"`
+	Target         string             `bson:"target,omitempty" json:"target,omitempty"`
+	Before         bson.M             `bson:"before,omitempty" json:"before,omitempty"`
+	After          bson.M             `bson:"after,omitempty" json:"after,omitempty"`
+	IP             string             `bson:"ip,omitempty" json:"ip,omitempty"`
+	RequestID      string             `bson:"requestID,omitempty" json:"requestID,omitempty"`
+	Timestamp      time.Time          `bson:"timestamp" json:"timestamp" validate:"required"`
+}
+
+type AuditQuery struct {
+	SubscriptionID string
+	Action         AuditAction
+	Limit          int
+	Cursor         string
+}
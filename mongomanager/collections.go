@@ -1,8 +1,12 @@
 package mongomanager
 
 const (
-	databaseSubscription = "subscription"
-	collectionSeats      = "seats"
+	databaseSubscription     = "subscription"
+	collectionSeats          = "seats"
+	collectionOwnerTransfers = "ownerTransfers"
+	collectionAuditLog       = "auditLog"
+	collectionInvoiceCounts  = "invoiceCounts"
+	collectionSeatLocks      = "seatLocks"
 
 	databaseProduct = "product"
 	collectionTrial = "trial"
@@ -12,11 +16,16 @@ const (
 
 	databaseNewsletters   = "newsletters"
 	collectionSubscribers = "subscribers"
+
+	databaseAPI           = "api"
+	collectionIdempotency = "idempotency"
+	collectionRateLimits  = "ratelimits"
 )
 
 var databases = map[string][]string{
-	databaseSubscription: {collectionSeats},
+	databaseSubscription: {collectionSeats, collectionOwnerTransfers, collectionAuditLog, collectionInvoiceCounts, collectionSeatLocks},
 	databaseProduct:      {collectionTrial},
 	databaseStripe:       {collectionUsers},
 	databaseNewsletters:  {collectionSubscribers},
+	databaseAPI:          {collectionIdempotency, collectionRateLimits},
 }
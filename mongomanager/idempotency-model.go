@@ -0,0 +1,15 @@
+package mongomanager
+
+import "time"
+
+type IdempotencyRecord struct {
+	Key         string    `bson:"key" validate:"required"`
+	UID         string    `bson:"uid" validate:"required"`
+	Route       string    `bson:"route" validate:"required"`
+	RequestHash string    `bson:"requestHash" validate:"required"`
+	Completed   bool      `bson:"completed"`
+	StatusCode  int       `bson:"statusCode,omitempty"`
+	ContentType string    `bson:"contentType,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"createdAt" validate:"required"`
+}
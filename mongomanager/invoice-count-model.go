@@ -0,0 +1,25 @@
+package mongomanager
+
+import "time"
+
+type InvoiceCount struct {
+	SubscriptionID    string    `bson:"subscriptionID" json:"subscriptionID" validate:"required"`
+	InvoiceIDs        []string  `bson:"invoiceIDs" json:"invoiceIDs"`
+	DeletedInvoiceIDs []string  `bson:"deletedInvoiceIDs" json:"deletedInvoiceIDs"`
+	Seeded            bool      `bson:"seeded" json:"seeded"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt" validate:"required"`
+}
+
+func (invoiceCount InvoiceCount) Count() int64 {
+	deleted := make(map[string]bool, len(invoiceCount.DeletedInvoiceIDs))
+	for _, invoiceID := range invoiceCount.DeletedInvoiceIDs {
+		deleted[invoiceID] = true
+	}
+	var count int64
+	for _, invoiceID := range invoiceCount.InvoiceIDs {
+		if !deleted[invoiceID] {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,95 @@
+package mongomanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func (mongoConnection *MongoConnection) ensureAuditLogIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("AuditLogSubscription"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "action", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("AuditLogSubscriptionAction"),
+		},
+	}
+	collection, err := mongoConnection.getCollection(context.Background(), databaseSubscription, collectionAuditLog)
+	if err != nil {
+		return err
+	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for audit log", zap.String("error", err.Error()))
+		return err
+	}
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
+
+func (mongoConnection *MongoConnection) CreateAuditEntry(ctx context.Context, entry AuditEntry) error {
+	err := ValidateStruct(entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = primitive.NilObjectID
+	return mongoConnection.createDocument(ctx, databaseSubscription, collectionAuditLog, entry)
+}
+
+func (mongoConnection *MongoConnection) FindAuditEntries(ctx context.Context, query AuditQuery) ([]AuditEntry, error) {
+	if query.SubscriptionID == "" {
+		return []AuditEntry{}, errors.New("subscription ID is empty")
+	}
+	filter := bson.M{"subscriptionID": query.SubscriptionID}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.Cursor != "" {
+		cursorID, err := primitive.ObjectIDFromHex(query.Cursor)
+		if err != nil {
+			return []AuditEntry{}, errors.New("invalid cursor")
+		}
+		filter["_id"] = bson.M{"$lt": cursorID}
+	}
+	opts := options.Find()
+	opts.SetLimit(int64(query.Limit))
+	opts.SetSort(bson.D{{Key: "_id", Value: -1}})
+	entries := []AuditEntry{}
+	err := mongoConnection.findDocuments(ctx, databaseSubscription, collectionAuditLog, filter, &entries, opts)
+	if err != nil {
+		return []AuditEntry{}, err
+	}
+	return entries, nil
+}
+
+func ToAuditDocument(value interface{}) bson.M {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var document bson.M
+	err = json.Unmarshal(data, &document)
+	if err != nil {
+		return bson.M{"value": value}
+	}
+	return document
+}
@@ -106,6 +106,22 @@ func (mongoConnection *MongoConnection) deleteDocument(ctx context.Context, data
 	return nil
 }
 
+func (mongoConnection *MongoConnection) deleteDocuments(ctx context.Context, databaseName, collectionName string, filter interface{}) (int64, error) {
+	collection, err := mongoConnection.getCollection(ctx, databaseName, collectionName)
+	if err != nil {
+		return 0, err
+	}
+	if filter == nil {
+		return 0, errors.New("filter is nil")
+	}
+	result, err := collection.DeleteMany(ctx, filter)
+	if err != nil {
+		mongoConnection.Log.Error("Error deleting documents", zap.Error(err))
+		return 0, errors.New("error deleting documents")
+	}
+	return result.DeletedCount, nil
+}
+
 func (mongoConnection *MongoConnection) findOneDocument(ctx context.Context, databaseName, collectionName string, filter interface{}) (*mongo.SingleResult, error) {
 	collection, err := mongoConnection.getCollection(ctx, databaseName, collectionName)
 	if err != nil {
@@ -0,0 +1,113 @@
+package mongomanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const idempotencyRecordTTL = 24 * time.Hour
+
+var ErrIdempotencyRecordExists = errors.New("idempotency record already exists")
+
+func (mongoConnection *MongoConnection) ensureIdempotencyIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "uid", Value: 1},
+				{Key: "key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetName("UniqueIdempotencyUIDKey"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "createdAt", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(int32(idempotencyRecordTTL.Seconds())).SetName("TTLIdempotencyCreatedAt"),
+		},
+	}
+	collection, err := mongoConnection.getCollection(context.Background(), databaseAPI, collectionIdempotency)
+	if err != nil {
+		return err
+	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for idempotency", zap.String("error", err.Error()))
+		return err
+	}
+
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
+
+func (mongoConnection *MongoConnection) CreateIdempotencyRecord(ctx context.Context, record IdempotencyRecord) error {
+	err := ValidateStruct(record)
+	if err != nil {
+		return err
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseAPI, collectionIdempotency)
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, record)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrIdempotencyRecordExists
+		}
+		mongoConnection.Log.Error("Error inserting idempotency record", zap.Error(err))
+		return errors.New("error inserting idempotency record")
+	}
+	return nil
+}
+
+func (mongoConnection *MongoConnection) GetIdempotencyRecord(ctx context.Context, uid, key string) (IdempotencyRecord, error) {
+	if uid == "" {
+		return IdempotencyRecord{}, errors.New("uid is empty")
+	}
+	if key == "" {
+		return IdempotencyRecord{}, errors.New("idempotency key is empty")
+	}
+	filter := bson.M{
+		"uid": uid,
+		"key": key,
+	}
+	singleResult, err := mongoConnection.findOneDocument(ctx, databaseAPI, collectionIdempotency, filter)
+	if err != nil {
+		return IdempotencyRecord{}, err
+	}
+	var record IdempotencyRecord
+	decodeErr := singleResult.Decode(&record)
+	if decodeErr != nil {
+		return IdempotencyRecord{}, decodeErr
+	}
+	return record, nil
+}
+
+func (mongoConnection *MongoConnection) CompleteIdempotencyRecord(ctx context.Context, record IdempotencyRecord) error {
+	filter := bson.M{
+		"uid": record.UID,
+		"key": record.Key,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"completed":   true,
+			"statusCode":  record.StatusCode,
+			"contentType": record.ContentType,
+			"body":        record.Body,
+		},
+	}
+	return mongoConnection.updateDocument(ctx, databaseAPI, collectionIdempotency, filter, update)
+}
+
+func (mongoConnection *MongoConnection) DeleteIdempotencyRecord(ctx context.Context, uid, key string) error {
+	filter := bson.M{
+		"uid": uid,
+		"key": key,
+	}
+	return mongoConnection.deleteDocument(ctx, databaseAPI, collectionIdempotency, filter)
+}
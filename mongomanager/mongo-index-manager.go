@@ -5,10 +5,26 @@ func (mongoConnection *MongoConnection) EnsureIndexes() error {
 	if err != nil {
 		return err
 	}
+	err = mongoConnection.ensureOwnerTransferIndexes()
+	if err != nil {
+		return err
+	}
+	err = mongoConnection.ensureAuditLogIndexes()
+	if err != nil {
+		return err
+	}
+	err = mongoConnection.ensureInvoiceCountIndexes()
+	if err != nil {
+		return err
+	}
 	err = mongoConnection.ensureNewsletterIndex()
 	if err != nil {
 		return err
 	}
+	err = mongoConnection.ensureIdempotencyIndexes()
+	if err != nil {
+		return err
+	}
 	mongoConnection.Log.Info("all required indexes are present")
 	return nil
 }
@@ -0,0 +1,96 @@
+package mongomanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func (mongoConnection *MongoConnection) ensureInvoiceCountIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetName("UniqueInvoiceCountSubscription"),
+		},
+	}
+	collection, err := mongoConnection.getCollection(context.Background(), databaseSubscription, collectionInvoiceCounts)
+	if err != nil {
+		return err
+	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for invoice counts", zap.String("error", err.Error()))
+		return err
+	}
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
+
+func (mongoConnection *MongoConnection) GetInvoiceCount(ctx context.Context, subscriptionID string) (InvoiceCount, error) {
+	if subscriptionID == "" {
+		return InvoiceCount{}, errors.New("subscription ID is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionInvoiceCounts)
+	if err != nil {
+		return InvoiceCount{}, err
+	}
+	var invoiceCount InvoiceCount
+	err = collection.FindOne(ctx, bson.M{"subscriptionID": subscriptionID}).Decode(&invoiceCount)
+	if err != nil {
+		return InvoiceCount{}, err
+	}
+	return invoiceCount, nil
+}
+
+// SeedInvoiceCount merges the invoices listed from Stripe with those already recorded by webhooks.
+func (mongoConnection *MongoConnection) SeedInvoiceCount(ctx context.Context, subscriptionID string, invoiceIDs []string) error {
+	update := bson.M{
+		"$addToSet": bson.M{"invoiceIDs": bson.M{"$each": invoiceIDs}},
+		"$set":      bson.M{"seeded": true, "updatedAt": time.Now()},
+	}
+	return mongoConnection.upsertInvoiceCount(ctx, subscriptionID, update)
+}
+
+func (mongoConnection *MongoConnection) AddInvoiceToCount(ctx context.Context, subscriptionID, invoiceID string) error {
+	update := bson.M{
+		"$addToSet": bson.M{"invoiceIDs": invoiceID},
+		"$set":      bson.M{"updatedAt": time.Now()},
+	}
+	return mongoConnection.upsertInvoiceCount(ctx, subscriptionID, update)
+}
+
+func (mongoConnection *MongoConnection) RemoveInvoiceFromCount(ctx context.Context, subscriptionID, invoiceID string) error {
+	update := bson.M{
+		"$addToSet": bson.M{"deletedInvoiceIDs": invoiceID},
+		"$set":      bson.M{"updatedAt": time.Now()},
+	}
+	return mongoConnection.upsertInvoiceCount(ctx, subscriptionID, update)
+}
+
+func (mongoConnection *MongoConnection) upsertInvoiceCount(ctx context.Context, subscriptionID string, update bson.M) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionInvoiceCounts)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"subscriptionID": subscriptionID}
+	opts := options.Update().SetUpsert(true)
+	_, err = collection.UpdateOne(ctx, filter, update, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		_, err = collection.UpdateOne(ctx, filter, update, opts)
+	}
+	if err != nil {
+		mongoConnection.Log.Error("Error updating invoice count", zap.String("subscriptionID", subscriptionID), zap.Error(err))
+		return errors.New("error updating invoice count")
+	}
+	return nil
+}
@@ -62,9 +62,11 @@ func (mongoConnection *MongoConnection) CheckMongoConnectability(ctx context.Con
 	return nil
 }
 
-func (mongoConnection *MongoConnection) CheckDatabaseAndCollectionExists(ctx context.Context) error {
+// EnsureDatabasesAndCollections creates missing collections up front, because collections cannot
+// be created implicitly inside the multi-document transactions that use them.
+func (mongoConnection *MongoConnection) EnsureDatabasesAndCollections(ctx context.Context) error {
 	for dbName, collections := range databases {
-		err := mongoConnection.ensureCollectionsExist(dbName, collections)
+		err := mongoConnection.ensureCollectionsExist(ctx, dbName, collections)
 		if err != nil {
 			return err
 		}
@@ -72,9 +74,9 @@ func (mongoConnection *MongoConnection) CheckDatabaseAndCollectionExists(ctx con
 	return nil
 }
 
-func (mongoConnection *MongoConnection) ensureCollectionsExist(dbName string, collectionNames []string) error {
+func (mongoConnection *MongoConnection) ensureCollectionsExist(ctx context.Context, dbName string, collectionNames []string) error {
 	database := mongoConnection.Client.Database(dbName)
-	existingCollections, err := database.ListCollectionNames(context.Background(), bson.M{})
+	existingCollections, err := database.ListCollectionNames(ctx, bson.M{})
 	if err != nil {
 		return errors.New("Failed to get collection names: " + err.Error())
 	}
@@ -85,10 +87,24 @@ func (mongoConnection *MongoConnection) ensureCollectionsExist(dbName string, co
 	}
 
 	for _, name := range collectionNames {
-		if !existingCollectionsMap[name] {
-			return errors.New("Collection " + name + " does not exist in database " + dbName)
+		if existingCollectionsMap[name] {
+			continue
 		}
+		err = database.CreateCollection(ctx, name)
+		if isNamespaceExists(err) {
+			continue
+		}
+		if err != nil {
+			return errors.New("Failed to create collection " + name + " in database " + dbName + ": " + err.Error())
+		}
+		mongoConnection.Log.Info("Created collection", zap.String("database", dbName), zap.String("collection", name))
 	}
 
 	return nil
 }
+
+// isNamespaceExists reports whether another instance created the collection concurrently.
+func isNamespaceExists(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Name == "NamespaceExists"
+}
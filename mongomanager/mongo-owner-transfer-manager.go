@@ -0,0 +1,138 @@
+package mongomanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrOwnerTransferPending    = errors.New("an owner transfer is already pending for this subscription")
+	ErrOwnerTransferNotPending = errors.New("owner transfer is not pending")
+)
+
+func (mongoConnection *MongoConnection) ensureOwnerTransferIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tokenHash", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetName("UniqueOwnerTransferTokenHash"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": OwnerTransferStatusPending}).SetName("UniquePendingOwnerTransferSubscription"),
+		},
+	}
+	collection, err := mongoConnection.getCollection(context.Background(), databaseSubscription, collectionOwnerTransfers)
+	if err != nil {
+		return err
+	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for owner transfers", zap.String("error", err.Error()))
+		return err
+	}
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
+
+func (mongoConnection *MongoConnection) CreateOwnerTransfer(ctx context.Context, ownerTransfer OwnerTransfer) error {
+	err := ValidateStruct(ownerTransfer)
+	if err != nil {
+		return err
+	}
+	err = mongoConnection.expireOwnerTransfers(ctx, ownerTransfer.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionOwnerTransfers)
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertOne(ctx, ownerTransfer)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrOwnerTransferPending
+		}
+		mongoConnection.Log.Error("Error creating owner transfer", zap.Error(err))
+		return errors.New("error creating owner transfer")
+	}
+	return nil
+}
+
+func (mongoConnection *MongoConnection) expireOwnerTransfers(ctx context.Context, subscriptionID string) error {
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionOwnerTransfers)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	filter := bson.M{
+		"subscriptionID": subscriptionID,
+		"status":         OwnerTransferStatusPending,
+		"expiresAt":      bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{"status": OwnerTransferStatusExpired, "updatedAt": now}}
+	_, err = collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		mongoConnection.Log.Error("Error expiring owner transfers", zap.Error(err))
+		return errors.New("error expiring owner transfers")
+	}
+	return nil
+}
+
+func (mongoConnection *MongoConnection) GetOwnerTransferByTokenHash(ctx context.Context, tokenHash string) (OwnerTransfer, error) {
+	if tokenHash == "" {
+		return OwnerTransfer{}, errors.New("token is empty")
+	}
+	return mongoConnection.getOwnerTransfer(ctx, bson.M{"tokenHash": tokenHash})
+}
+
+func (mongoConnection *MongoConnection) GetPendingOwnerTransfer(ctx context.Context, subscriptionID string) (OwnerTransfer, error) {
+	if subscriptionID == "" {
+		return OwnerTransfer{}, errors.New("subscription ID is empty")
+	}
+	return mongoConnection.getOwnerTransfer(ctx, bson.M{"subscriptionID": subscriptionID, "status": OwnerTransferStatusPending})
+}
+
+func (mongoConnection *MongoConnection) getOwnerTransfer(ctx context.Context, filter bson.M) (OwnerTransfer, error) {
+	singleResult, err := mongoConnection.findOneDocument(ctx, databaseSubscription, collectionOwnerTransfers, filter)
+	if err != nil {
+		mongoConnection.Log.Warn("Error finding owner transfer", zap.Error(err))
+		return OwnerTransfer{}, errors.New("owner transfer not found")
+	}
+	var ownerTransfer OwnerTransfer
+	err = singleResult.Decode(&ownerTransfer)
+	if err != nil {
+		return OwnerTransfer{}, err
+	}
+	return ownerTransfer, nil
+}
+
+func (mongoConnection *MongoConnection) UpdateOwnerTransferStatus(ctx context.Context, tokenHash string, from, to OwnerTransferStatus) error {
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionOwnerTransfers)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"status":    from,
+	}
+	update := bson.M{"$set": bson.M{"status": to, "updatedAt": time.Now()}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		mongoConnection.Log.Error("Error updating owner transfer", zap.Error(err))
+		return errors.New("error updating owner transfer")
+	}
+	if result.MatchedCount != 1 {
+		return ErrOwnerTransferNotPending
+	}
+	return nil
+}
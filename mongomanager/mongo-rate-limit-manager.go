@@ -0,0 +1,82 @@
+package mongomanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+func (mongoConnection *MongoConnection) EnsureRateLimitIndexes() error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("UniqueRateLimitKey"),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("TTLRateLimitExpiresAt"),
+		},
+	}
+	collection, err := mongoConnection.getCollection(context.Background(), databaseAPI, collectionRateLimits)
+	if err != nil {
+		return err
+	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for rate limits", zap.String("error", err.Error()))
+		return err
+	}
+
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
+
+func (mongoConnection *MongoConnection) TakeRateLimitToken(ctx context.Context, key string, burst, ratePerSecond float64, ttl time.Duration) (RateLimitBucket, error) {
+	if key == "" {
+		return RateLimitBucket{}, errors.New("rate limit key is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseAPI, collectionRateLimits)
+	if err != nil {
+		return RateLimitBucket{}, err
+	}
+	now := time.Now()
+	elapsedSeconds := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updatedAt", now}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		burst,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", burst}},
+			bson.M{"$multiply": bson.A{elapsedSeconds, ratePerSecond}},
+		}},
+	}}
+	hasToken := bson.M{"$gte": bson.A{"$tokens", 1}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens":    refilled,
+			"updatedAt": now,
+			"expiresAt": now.Add(ttl),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": hasToken,
+			"tokens":  bson.M{"$cond": bson.A{hasToken, bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var bucket RateLimitBucket
+	err = collection.FindOneAndUpdate(ctx, bson.M{"key": key}, pipeline, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		err = collection.FindOneAndUpdate(ctx, bson.M{"key": key}, pipeline, opts).Decode(&bucket)
+	}
+	if err != nil {
+		mongoConnection.Log.Error("Error taking rate limit token", zap.Error(err))
+		return RateLimitBucket{}, errors.New("error taking rate limit token")
+	}
+	return bucket, nil
+}
@@ -2,37 +2,117 @@ package mongomanager
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
 
 func (mongoConnection *MongoConnection) ensureSeatIndex() error {
-	indexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "subscriptionID", Value: 1},
-			{Key: "email", Value: 1},
+	indexModels := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "email", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetName("UniqueSubscriptionEmail"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "email", Value: 1},
+				{Key: "uid", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionEmailUID"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "emailLower", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionEmailLower"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "invitedAt", Value: 1},
+				{Key: "uid", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionInvitedAtUID"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "joinedAt", Value: 1},
+				{Key: "uid", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionJoinedAtUID"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "uid", Value: 1},
+				{Key: "emailVerified", Value: 1},
+			},
+			Options: options.Index().SetName("UIDEmailVerified"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "roles", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionRoles"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "subscriptionID", Value: 1},
+				{Key: "emailVerified", Value: 1},
+			},
+			Options: options.Index().SetName("SubscriptionEmailVerified"),
 		},
-		Options: options.Index().SetUnique(true).SetName("UniqueSubscriptionEmail"),
 	}
 	collection, err := mongoConnection.getCollection(context.Background(), databaseSubscription, collectionSeats)
 	if err != nil {
 		return err
 	}
-	name, err := collection.Indexes().CreateOne(context.Background(), indexModel)
+	err = mongoConnection.backfillSeatEMailLower(collection)
 	if err != nil {
-		mongoConnection.Log.Error("Error creating index for seats", zap.String("error", err.Error()))
 		return err
 	}
+	names, err := collection.Indexes().CreateMany(context.Background(), indexModels)
+	if err != nil {
+		mongoConnection.Log.Error("Error creating indexes for seats", zap.String("error", err.Error()))
+		return err
+	}
+
+	mongoConnection.Log.Info("Required indexes for collection "+collection.Name()+" are present.", zap.Strings("indexes", names))
+	return nil
+}
 
-	mongoConnection.Log.Info("Required index for collection " + collection.Name() + " is present. Index: " + name)
+// backfillSeatEMailLower sets emailLower on seats created before the field existed.
+func (mongoConnection *MongoConnection) backfillSeatEMailLower(collection *mongo.Collection) error {
+	filter := bson.M{"emailLower": bson.M{"$exists": false}}
+	set := bson.M{"emailLower": bson.M{"$toLower": "$email"}}
+	result, err := collection.UpdateMany(context.Background(), filter, mongo.Pipeline{{{Key: "$set", Value: set}}})
+	if err != nil {
+		mongoConnection.Log.Error("Error backfilling seat emailLower", zap.Error(err))
+		return err
+	}
+	if result.ModifiedCount > 0 {
+		mongoConnection.Log.Info("Backfilled seat emailLower", zap.Int64("seats", result.ModifiedCount))
+	}
 	return nil
 }
 
 func (mongoConnection *MongoConnection) CreateSeat(ctx context.Context, seat Seat) error {
+	seat.EMailLower = strings.ToLower(seat.EMail)
 	err := ValidateStruct(seat)
 	if err != nil {
 		return err
@@ -49,17 +129,57 @@ func (mongoConnection *MongoConnection) GetAllSeats(ctx context.Context, subscri
 }
 
 func (mongoConnection *MongoConnection) GetSeats(ctx context.Context, subscriptionID string, pageIndex int, pageSize int) ([]Seat, error) {
-	if subscriptionID == "" {
+	return mongoConnection.FindSeats(ctx, SeatQuery{
+		SubscriptionID: subscriptionID,
+		SortBy:         SeatSortEMail,
+		Skip:           pageIndex * pageSize,
+		Limit:          pageSize,
+	})
+}
+
+func (mongoConnection *MongoConnection) CountSeatsMatching(ctx context.Context, query SeatQuery) (int64, error) {
+	if query.SubscriptionID == "" {
+		return 0, errors.New("subscription ID is empty")
+	}
+	return mongoConnection.countDocuments(ctx, databaseSubscription, collectionSeats, seatFilter(query))
+}
+
+func (mongoConnection *MongoConnection) FindSeats(ctx context.Context, query SeatQuery) ([]Seat, error) {
+	if query.SubscriptionID == "" {
 		return []Seat{}, errors.New("subscription ID is empty")
 	}
-	filter := bson.M{
-		"subscriptionID": subscriptionID,
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = SeatSortEMail
 	}
-	var seats []Seat
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+	filter := seatFilter(query)
 	opts := options.Find()
-	opts.SetLimit(int64(pageSize))
-	opts.SetSkip(int64(pageIndex * pageSize))
-	opts.SetSort(bson.D{{Key: "email", Value: 1}})
+	opts.SetLimit(int64(query.Limit))
+	opts.SetSort(bson.D{
+		{Key: string(sortBy), Value: direction},
+		{Key: "uid", Value: direction},
+	})
+	if query.Cursor != "" {
+		cursor, err := decodeSeatCursor(query.Cursor)
+		if err != nil {
+			return []Seat{}, err
+		}
+		if cursor.SortBy != sortBy || cursor.Descending != query.Descending {
+			return []Seat{}, errors.New("cursor does not match sort order")
+		}
+		cursor.anchor, err = mongoConnection.getSeatByID(ctx, query.SubscriptionID, cursor.ID)
+		if err != nil {
+			return []Seat{}, errors.New("cursor expired")
+		}
+		filter = bson.M{"$and": []bson.M{filter, cursor.filter()}}
+	} else {
+		opts.SetSkip(int64(query.Skip))
+	}
+	seats := []Seat{}
 	err := mongoConnection.findDocuments(ctx, databaseSubscription, collectionSeats, filter, &seats, opts)
 	if err != nil {
 		return []Seat{}, err
@@ -67,6 +187,95 @@ func (mongoConnection *MongoConnection) GetSeats(ctx context.Context, subscripti
 	return seats, nil
 }
 
+func seatFilter(query SeatQuery) bson.M {
+	filter := bson.M{
+		"subscriptionID": query.SubscriptionID,
+	}
+	if query.Search != "" {
+		filter["emailLower"] = bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(query.Search))}
+	}
+	if query.Role != "" {
+		filter["roles"] = query.Role
+	}
+	if query.EMailVerified != nil {
+		filter["emailVerified"] = *query.EMailVerified
+	}
+	return filter
+}
+
+func EncodeSeatCursor(query SeatQuery, seat Seat) (string, error) {
+	if seat.ID.IsZero() {
+		return "", errors.New("seat ID is empty")
+	}
+	cursor := seatCursor{
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		ID:         seat.ID,
+	}
+	if cursor.SortBy == "" {
+		cursor.SortBy = SeatSortEMail
+	}
+	switch cursor.SortBy {
+	case SeatSortEMail, SeatSortInvitedAt, SeatSortJoinedAt:
+	default:
+		return "", errors.New("unsupported sort field " + string(cursor.SortBy))
+	}
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSeatCursor(encoded string) (seatCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return seatCursor{}, errors.New("invalid cursor")
+	}
+	var cursor seatCursor
+	err = json.Unmarshal(data, &cursor)
+	if err != nil || cursor.ID.IsZero() {
+		return seatCursor{}, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+func (cursor seatCursor) filter() bson.M {
+	field := string(cursor.SortBy)
+	uid := cursor.anchor.UID
+	var value interface{}
+	switch {
+	case cursor.SortBy == SeatSortEMail:
+		value = cursor.anchor.EMail
+	case cursor.SortBy == SeatSortInvitedAt && cursor.anchor.InvitedAt != nil:
+		value = *cursor.anchor.InvitedAt
+	case cursor.SortBy == SeatSortJoinedAt && cursor.anchor.JoinedAt != nil:
+		value = *cursor.anchor.JoinedAt
+	}
+	compare := "$gt"
+	if cursor.Descending {
+		compare = "$lt"
+	}
+	// Seats without a timestamp sort before all others in ascending order and after all others in descending order.
+	if value == nil {
+		if cursor.Descending {
+			return bson.M{field: nil, "uid": bson.M{compare: uid}}
+		}
+		return bson.M{"$or": []bson.M{
+			{field: nil, "uid": bson.M{compare: uid}},
+			{field: bson.M{"$ne": nil}},
+		}}
+	}
+	conditions := []bson.M{
+		{field: bson.M{compare: value}},
+		{field: value, "uid": bson.M{compare: uid}},
+	}
+	if cursor.Descending && cursor.SortBy != SeatSortEMail {
+		conditions = append(conditions, bson.M{field: nil})
+	}
+	return bson.M{"$or": conditions}
+}
+
 func (mongoConnection *MongoConnection) GetSeat(ctx context.Context, subscriptionID, uid string) (Seat, error) {
 	if subscriptionID == "" {
 		return Seat{}, errors.New("subscription ID is empty")
@@ -91,6 +300,23 @@ func (mongoConnection *MongoConnection) GetSeat(ctx context.Context, subscriptio
 	return seat, nil
 }
 
+func (mongoConnection *MongoConnection) getSeatByID(ctx context.Context, subscriptionID string, id primitive.ObjectID) (Seat, error) {
+	filter := bson.M{
+		"_id":            id,
+		"subscriptionID": subscriptionID,
+	}
+	singleResult, err := mongoConnection.findOneDocument(ctx, databaseSubscription, collectionSeats, filter)
+	if err != nil {
+		return Seat{}, err
+	}
+	var seat Seat
+	err = singleResult.Decode(&seat)
+	if err != nil {
+		return Seat{}, err
+	}
+	return seat, nil
+}
+
 func (mongoConnection *MongoConnection) GetOwnerSeat(ctx context.Context, subscriptionID string) (Seat, error) {
 	if subscriptionID == "" {
 		return Seat{}, errors.New("subscription ID is empty")
@@ -114,21 +340,213 @@ func (mongoConnection *MongoConnection) GetOwnerSeat(ctx context.Context, subscr
 	return seat, nil
 }
 
-func (mongoConnection *MongoConnection) UpdateSeat(ctx context.Context, seat Seat) error {
+func (mongoConnection *MongoConnection) DeleteSeat(ctx context.Context, seat Seat) error {
 	filter := bson.M{
 		"subscriptionID": seat.SubscriptionID,
 		"uid":            seat.UID,
 	}
-	update := bson.M{
-		"$set": seat,
+	return mongoConnection.deleteDocument(ctx, databaseSubscription, collectionSeats, filter)
+}
+
+func (mongoConnection *MongoConnection) DeleteSeatsBySubscription(ctx context.Context, subscriptionID string) (int64, error) {
+	if subscriptionID == "" {
+		return 0, errors.New("subscription ID is empty")
 	}
-	return mongoConnection.updateDocument(ctx, databaseSubscription, collectionSeats, filter, update)
+	filter := bson.M{
+		"subscriptionID": subscriptionID,
+	}
+	return mongoConnection.deleteDocuments(ctx, databaseSubscription, collectionSeats, filter)
 }
 
-func (mongoConnection *MongoConnection) DeleteSeat(ctx context.Context, seat Seat) error {
+func (mongoConnection *MongoConnection) DeleteSeats(ctx context.Context, subscriptionID string, uids []string) (int64, error) {
+	if subscriptionID == "" {
+		return 0, errors.New("subscription ID is empty")
+	}
+	if len(uids) == 0 {
+		return 0, nil
+	}
 	filter := bson.M{
-		"subscriptionID": seat.SubscriptionID,
-		"uid":            seat.UID,
+		"subscriptionID": subscriptionID,
+		"uid":            bson.M{"$in": uids},
+		"roles":          bson.M{"$ne": RoleOwner},
 	}
-	return mongoConnection.deleteDocument(ctx, databaseSubscription, collectionSeats, filter)
+	return mongoConnection.deleteDocuments(ctx, databaseSubscription, collectionSeats, filter)
+}
+
+func (mongoConnection *MongoConnection) GetSeatsByUIDs(ctx context.Context, subscriptionID string, uids []string) ([]Seat, error) {
+	if subscriptionID == "" {
+		return []Seat{}, errors.New("subscription ID is empty")
+	}
+	filter := bson.M{
+		"subscriptionID": subscriptionID,
+		"uid":            bson.M{"$in": uids},
+	}
+	seats := []Seat{}
+	err := mongoConnection.findDocuments(ctx, databaseSubscription, collectionSeats, filter, &seats, options.Find())
+	if err != nil {
+		return []Seat{}, err
+	}
+	return seats, nil
+}
+
+func (mongoConnection *MongoConnection) GetSeatsOfUser(ctx context.Context, uid string) ([]Seat, error) {
+	if uid == "" {
+		return []Seat{}, errors.New("uid is empty")
+	}
+	filter := bson.M{
+		"uid": uid,
+	}
+	seats := []Seat{}
+	err := mongoConnection.findDocuments(ctx, databaseSubscription, collectionSeats, filter, &seats, options.Find())
+	if err != nil {
+		return []Seat{}, err
+	}
+	return seats, nil
+}
+
+func (mongoConnection *MongoConnection) StreamSeats(ctx context.Context, subscriptionID string, handle func(Seat) error) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionSeats)
+	if err != nil {
+		return err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "email", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"subscriptionID": subscriptionID}, opts)
+	if err != nil {
+		mongoConnection.Log.Error("Error finding seats", zap.Error(err))
+		return errors.New("error finding seats")
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var seat Seat
+		err = cursor.Decode(&seat)
+		if err != nil {
+			return err
+		}
+		err = handle(seat)
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (mongoConnection *MongoConnection) MarkSeatsEMailVerified(ctx context.Context, uid string) (int64, error) {
+	if uid == "" {
+		return 0, errors.New("uid is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionSeats)
+	if err != nil {
+		return 0, err
+	}
+	filter := bson.M{
+		"uid":           uid,
+		"emailVerified": bson.M{"$ne": true},
+	}
+	set := bson.M{
+		"emailVerified": true,
+		"joinedAt":      bson.M{"$ifNull": bson.A{"$joinedAt", time.Now()}},
+	}
+	result, err := collection.UpdateMany(ctx, filter, mongo.Pipeline{{{Key: "$set", Value: set}}})
+	if err != nil {
+		mongoConnection.Log.Error("Error updating seats email verification", zap.String("uid", uid), zap.Error(err))
+		return 0, errors.New("error updating seats")
+	}
+	return result.ModifiedCount, nil
+}
+
+func (mongoConnection *MongoConnection) GetUnverifiedSeatUIDs(ctx context.Context) ([]string, error) {
+	return mongoConnection.unverifiedSeatUIDs(ctx, bson.M{"emailVerified": false})
+}
+
+func (mongoConnection *MongoConnection) GetUnverifiedSeatUIDsOfSubscription(ctx context.Context, subscriptionID string) ([]string, error) {
+	if subscriptionID == "" {
+		return nil, errors.New("subscription ID is empty")
+	}
+	return mongoConnection.unverifiedSeatUIDs(ctx, bson.M{"subscriptionID": subscriptionID, "emailVerified": false})
+}
+
+func (mongoConnection *MongoConnection) unverifiedSeatUIDs(ctx context.Context, filter bson.M) ([]string, error) {
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionSeats)
+	if err != nil {
+		return nil, err
+	}
+	values, err := collection.Distinct(ctx, "uid", filter)
+	if err != nil {
+		mongoConnection.Log.Error("Error finding unverified seats", zap.Error(err))
+		return nil, errors.New("error finding unverified seats")
+	}
+	uids := make([]string, 0, len(values))
+	for _, value := range values {
+		if uid, ok := value.(string); ok {
+			uids = append(uids, uid)
+		}
+	}
+	return uids, nil
+}
+
+func (mongoConnection *MongoConnection) AddSeatRole(ctx context.Context, subscriptionID, uid string, role Role) error {
+	return mongoConnection.updateSeatRoles(ctx, subscriptionID, uid, bson.M{"$addToSet": bson.M{"roles": role}})
+}
+
+func (mongoConnection *MongoConnection) SetSeatRoles(ctx context.Context, subscriptionID, uid string, roles []Role) error {
+	if len(roles) == 0 {
+		return errors.New("roles are empty")
+	}
+	return mongoConnection.updateSeatRoles(ctx, subscriptionID, uid, bson.M{"$set": bson.M{"roles": roles}})
+}
+
+func (mongoConnection *MongoConnection) RemoveSeatRole(ctx context.Context, subscriptionID, uid string, role Role) error {
+	return mongoConnection.updateSeatRoles(ctx, subscriptionID, uid, bson.M{"$pull": bson.M{"roles": role}})
+}
+
+func (mongoConnection *MongoConnection) updateSeatRoles(ctx context.Context, subscriptionID, uid string, update bson.M) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is empty")
+	}
+	if uid == "" {
+		return errors.New("uid is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionSeats)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{
+		"subscriptionID": subscriptionID,
+		"uid":            uid,
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		mongoConnection.Log.Error("Error updating seat roles", zap.Error(err))
+		return errors.New("error updating seat roles")
+	}
+	if result.MatchedCount != 1 {
+		return errors.New("seat not found")
+	}
+	return nil
+}
+
+// LockSeats writes the per-subscription lock document so that concurrent
+// transactions reading the seats of the same subscription conflict and retry.
+func (mongoConnection *MongoConnection) LockSeats(ctx context.Context, subscriptionID string) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseSubscription, collectionSeatLocks)
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"_id": subscriptionID}
+	update := bson.M{
+		"$inc": bson.M{"version": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+	_, err = collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		mongoConnection.Log.Warn("Error locking seats", zap.String("subscriptionID", subscriptionID), zap.Error(err))
+		return err
+	}
+	return nil
 }
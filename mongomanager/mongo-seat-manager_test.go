@@ -0,0 +1,69 @@
+package mongomanager
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSeatCursorRoundTrip(t *testing.T) {
+	seat := Seat{ID: primitive.NewObjectID(), SubscriptionID: "sub_a", UID: "uid", EMail: "User@Example.com"}
+	for _, sortBy := range []SeatSortField{"", SeatSortEMail, SeatSortInvitedAt, SeatSortJoinedAt} {
+		encoded, err := EncodeSeatCursor(SeatQuery{SortBy: sortBy, Descending: true}, seat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(strings.ToLower(string(data)), "example.com") || strings.Contains(string(data), seat.UID) {
+			t.Errorf("cursor exposes seat data: %s", data)
+		}
+		cursor, err := decodeSeatCursor(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSortBy := sortBy
+		if wantSortBy == "" {
+			wantSortBy = SeatSortEMail
+		}
+		if cursor.ID != seat.ID || cursor.SortBy != wantSortBy || !cursor.Descending {
+			t.Errorf("decodeSeatCursor() = %+v", cursor)
+		}
+	}
+}
+
+func TestSeatCursorRejectsInvalid(t *testing.T) {
+	_, err := EncodeSeatCursor(SeatQuery{}, Seat{UID: "uid"})
+	if err == nil {
+		t.Error("expected an error for a seat without ID")
+	}
+	_, err = EncodeSeatCursor(SeatQuery{SortBy: "roles"}, Seat{ID: primitive.NewObjectID()})
+	if err == nil {
+		t.Error("expected an error for an unsupported sort field")
+	}
+	for _, encoded := range []string{"not base64!", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"email"}`))} {
+		_, err = decodeSeatCursor(encoded)
+		if err == nil {
+			t.Errorf("decodeSeatCursor(%q) succeeded", encoded)
+		}
+	}
+}
+
+func TestSeatFilterSearchIsAnchoredPrefix(t *testing.T) {
+	filter := seatFilter(SeatQuery{SubscriptionID: "sub_a", Search: "User.Name+1"})
+	search, ok := filter["emailLower"].(bson.M)
+	if !ok {
+		t.Fatalf("filter = %v, want an emailLower condition", filter)
+	}
+	if search["$regex"] != `^user\.name\+1` {
+		t.Errorf("$regex = %v", search["$regex"])
+	}
+	if _, ok := search["$options"]; ok {
+		t.Error("search must not use regex options, so it can use the index")
+	}
+}
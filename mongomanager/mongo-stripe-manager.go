@@ -42,3 +42,22 @@ func (mongoConnection *MongoConnection) GetUser(ctx context.Context, userFilter
 	}
 	return user, nil
 }
+
+func (mongoConnection *MongoConnection) TransferUser(ctx context.Context, sourceUID, destinationUID string) error {
+	if sourceUID == "" || destinationUID == "" {
+		return errors.New("uid is empty")
+	}
+	collection, err := mongoConnection.getCollection(ctx, databaseStripe, collectionUsers)
+	if err != nil {
+		return err
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"uid": sourceUID}, bson.M{"$set": bson.M{"uid": destinationUID}})
+	if err != nil {
+		mongoConnection.Log.Error("Error transferring user", zap.Error(err))
+		return errors.New("error transferring user")
+	}
+	if result.MatchedCount != 1 {
+		return errors.New("user not found")
+	}
+	return nil
+}
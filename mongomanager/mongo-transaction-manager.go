@@ -0,0 +1,25 @@
+package mongomanager
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+func (mongoConnection *MongoConnection) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := mongoConnection.Client.StartSession()
+	if err != nil {
+		mongoConnection.Log.Error("Error starting session", zap.Error(err))
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionContext)
+	})
+	if err != nil {
+		mongoConnection.Log.Warn("Transaction aborted", zap.Error(err))
+		return err
+	}
+	return nil
+}
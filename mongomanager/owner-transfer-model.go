@@ -0,0 +1,26 @@
+package mongomanager
+
+import "time"
+
+type OwnerTransferStatus string
+
+const (
+	OwnerTransferStatusPending  OwnerTransferStatus = "pending"
+	OwnerTransferStatusAccepted OwnerTransferStatus = "accepted"
+	OwnerTransferStatusDeclined OwnerTransferStatus = "declined"
+	OwnerTransferStatusCanceled OwnerTransferStatus = "canceled"
+	OwnerTransferStatusExpired  OwnerTransferStatus = "expired"
+)
+
+type OwnerTransfer struct {
+	SubscriptionID   string              `bson:"subscriptionID" json:"subscriptionID" validate:"required"`
+	SourceUID        string              `bson:"sourceUID" json:"sourceUID" validate:"required"`
+	SourceEMail      string              `bson:"sourceEMail" json:"sourceEMail" validate:"required"`
+	DestinationUID   string              `bson:"destinationUID" json:"destinationUID" validate:"required"`
+	DestinationEMail string              `bson:"destinationEMail" json:"destinationEMail" validate:"required"`
+	TokenHash        string              `bson:"tokenHash" json:"-" validate:"required"`
+	Status           OwnerTransferStatus `bson:"status" json:"status" validate:"required"`
+	CreatedAt        time.Time           `bson:"createdAt" json:"createdAt" validate:"required"`
+	ExpiresAt        time.Time           `bson:"expiresAt" json:"expiresAt" validate:"required"`
+	UpdatedAt        time.Time           `bson:"updatedAt" json:"updatedAt" validate:"required"`
+}
@@ -8,33 +8,53 @@ import (
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 )
 
-func (mongoConnection *MongoConnection) HasPermission(ctx context.Context, tokenDetails firebasemanager.TokenDetails, subscriptionID string, requiredRoles []Role) error {
+func (mongoConnection *MongoConnection) HasPermission(ctx context.Context, tokenDetails firebasemanager.TokenDetails, subscriptionID string, permission Permission) error {
 	seat, err := mongoConnection.GetSeat(ctx, subscriptionID, tokenDetails.UID)
 	if err != nil {
 		mongoConnection.Log.Warn("user with UID " + tokenDetails.UID + " tried to access subscriptionID " + subscriptionID + " error: " + err.Error())
 		return errors.New(http.StatusText(http.StatusForbidden))
 	}
-	if !ContainsRole(seat, requiredRoles) {
-		mongoConnection.Log.Warn("user with UID " + tokenDetails.UID + " is missing role " + " on subscriptionID " + subscriptionID)
+	if !SeatHasPermission(seat, permission) {
+		mongoConnection.Log.Warn("user with UID " + tokenDetails.UID + " is missing permission " + string(permission) + " on subscriptionID " + subscriptionID)
 		return errors.New(http.StatusText(http.StatusForbidden))
 	}
 
 	return nil
 }
 
-func ContainsRole(seat Seat, requiredRoles []Role) bool {
-	// Convert requiredRoles slice to a map for constant-time lookups
-	roleMap := make(map[Role]bool)
-	for _, role := range requiredRoles {
-		roleMap[role] = true
+func SeatHasPermission(seat Seat, permission Permission) bool {
+	for _, seatPermission := range SeatPermissions(seat) {
+		if seatPermission == permission {
+			return true
+		}
 	}
+	return false
+}
 
-	// Check if seat has any of the required roles or is a RoleOwner
-	for _, seatRole := range seat.Roles {
-		if roleMap[seatRole] || seatRole == RoleOwner {
-			return true
+func SeatPermissions(seat Seat) []Permission {
+	granted := make(map[Permission]bool)
+	for _, role := range seat.Roles {
+		for _, permission := range rolePermissions[role] {
+			granted[permission] = true
 		}
 	}
+	// Keep the order of allPermissions so the result is stable.
+	permissions := []Permission{}
+	for _, permission := range allPermissions {
+		if granted[permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
 
+func CanGrantRole(seat Seat, role Role) bool {
+	for _, seatRole := range seat.Roles {
+		for _, grantable := range roleGrants[seatRole] {
+			if grantable == role {
+				return true
+			}
+		}
+	}
 	return false
 }
@@ -0,0 +1,84 @@
+package mongomanager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeatPermissions(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []Role
+		want  []Permission
+	}{
+		{name: "no roles", roles: nil, want: []Permission{}},
+		{name: "unknown role", roles: []Role{"Guest"}, want: []Permission{}},
+		{name: "owner", roles: []Role{RoleOwner}, want: allPermissions},
+		{
+			name:  "administrator",
+			roles: []Role{RoleAdministrator},
+			want:  []Permission{PermissionSubscriptionRead, PermissionSubscriptionCancel, PermissionSeatsRead, PermissionSeatsWrite, PermissionAuditLogRead},
+		},
+		{name: "user", roles: []Role{RoleUser}, want: []Permission{PermissionSubscriptionRead}},
+		{
+			name:  "billing",
+			roles: []Role{RoleBilling},
+			want:  []Permission{PermissionSubscriptionRead, PermissionInvoicesRead, PermissionBillingRead, PermissionBillingWrite},
+		},
+		{
+			name:  "billing and administrator in allPermissions order",
+			roles: []Role{RoleBilling, RoleAdministrator},
+			want: []Permission{
+				PermissionSubscriptionRead, PermissionSubscriptionCancel, PermissionSeatsRead, PermissionSeatsWrite,
+				PermissionInvoicesRead, PermissionBillingRead, PermissionBillingWrite, PermissionAuditLogRead,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := SeatPermissions(Seat{Roles: test.roles})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("SeatPermissions() = %v, want %v", got, test.want)
+			}
+			for _, permission := range allPermissions {
+				want := false
+				for _, granted := range test.want {
+					want = want || granted == permission
+				}
+				if SeatHasPermission(Seat{Roles: test.roles}, permission) != want {
+					t.Errorf("SeatHasPermission(%s) = %t, want %t", permission, !want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCanGrantRole(t *testing.T) {
+	allRoles := []Role{RoleOwner, RoleAdministrator, RoleUser, RoleBilling}
+	tests := []struct {
+		name  string
+		roles []Role
+		grant []Role
+	}{
+		{name: "no roles", roles: nil, grant: nil},
+		{name: "owner", roles: []Role{RoleOwner}, grant: []Role{RoleAdministrator, RoleUser, RoleBilling}},
+		{name: "administrator", roles: []Role{RoleAdministrator}, grant: []Role{RoleAdministrator, RoleUser}},
+		{name: "user", roles: []Role{RoleUser}, grant: nil},
+		{name: "billing", roles: []Role{RoleBilling}, grant: nil},
+		{name: "billing and administrator", roles: []Role{RoleBilling, RoleAdministrator}, grant: []Role{RoleAdministrator, RoleUser}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, role := range allRoles {
+				want := false
+				for _, grantable := range test.grant {
+					want = want || grantable == role
+				}
+				got := CanGrantRole(Seat{Roles: test.roles}, role)
+				if got != want {
+					t.Errorf("CanGrantRole(%s) = %t, want %t", role, got, want)
+				}
+			}
+		})
+	}
+}
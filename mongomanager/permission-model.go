@@ -0,0 +1,52 @@
+package mongomanager
+
+type Permission string
+
+const (
+	PermissionSubscriptionRead   Permission = "subscription.read"
+	PermissionSubscriptionCancel Permission = "subscription.cancel"
+	PermissionSeatsRead          Permission = "seats.read"
+	PermissionSeatsWrite         Permission = "seats.write"
+	PermissionInvoicesRead       Permission = "invoices.read"
+	PermissionBillingRead        Permission = "billing.read"
+	PermissionBillingWrite       Permission = "billing.write"
+	PermissionAuditLogRead       Permission = "auditLog.read"
+	PermissionOwnerTransfer      Permission = "ownerTransfer.manage"
+)
+
+var allPermissions = []Permission{
+	PermissionSubscriptionRead,
+	PermissionSubscriptionCancel,
+	PermissionSeatsRead,
+	PermissionSeatsWrite,
+	PermissionInvoicesRead,
+	PermissionBillingRead,
+	PermissionBillingWrite,
+	PermissionAuditLogRead,
+	PermissionOwnerTransfer,
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: allPermissions,
+	RoleAdministrator: {
+		PermissionSubscriptionRead,
+		PermissionSubscriptionCancel,
+		PermissionSeatsRead,
+		PermissionSeatsWrite,
+		PermissionAuditLogRead,
+	},
+	RoleUser: {
+		PermissionSubscriptionRead,
+	},
+	RoleBilling: {
+		PermissionSubscriptionRead,
+		PermissionInvoicesRead,
+		PermissionBillingRead,
+		PermissionBillingWrite,
+	},
+}
+
+var roleGrants = map[Role][]Role{
+	RoleOwner:         {RoleAdministrator, RoleUser, RoleBilling},
+	RoleAdministrator: {RoleAdministrator, RoleUser},
+}
@@ -0,0 +1,11 @@
+package mongomanager
+
+import "time"
+
+type RateLimitBucket struct {
+	Key       string    `bson:"key"`
+	Tokens    float64   `bson:"tokens"`
+	Allowed   bool      `bson:"allowed"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
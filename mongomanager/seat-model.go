@@ -1,5 +1,11 @@
 package mongomanager
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type Role string
 
 const (
@@ -10,9 +16,42 @@ const (
 )
 
 type Seat struct {
-	SubscriptionID string `bson:"subscriptionID" json:"subscriptionID" validate:"required"`
-	UID            string `bson:"uid" json:"uid" validate:"required"`
-	EMail          string `bson:"email" json:"email" validate:"required"`
-	EMailVerified  *bool  `bson:"emailVerified" json:"emailVerified" validate:"required"`
-	Roles          []Role `bson:"roles" json:"roles" validate:"required"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	SubscriptionID string             `bson:"subscriptionID" json:"subscriptionID" validate:"required"`
+	UID            string             `bson:"uid" json:"uid" validate:"required"`
+	EMail          string             `bson:"email" json:"email" validate:"required"`
+	EMailLower     string             `bson:"emailLower" json:"-"`
+	EMailVerified  *bool              `bson:"emailVerified" json:"emailVerified" validate:"required"`
+	Roles          []Role             `bson:"roles" json:"roles" validate:"required"`
+	InvitedAt      *time.Time         `bson:"invitedAt,omitempty" json:"invitedAt,omitempty"`
+	JoinedAt       *time.Time         `bson:"joinedAt,omitempty" json:"joinedAt,omitempty"`
+}
+
+type SeatSortField string
+
+const (
+	SeatSortEMail     SeatSortField = "email"
+	SeatSortInvitedAt SeatSortField = "invitedAt"
+	SeatSortJoinedAt  SeatSortField = "joinedAt"
+)
+
+type SeatQuery struct {
+	SubscriptionID string
+	Search         string
+	Role           Role
+	EMailVerified  *bool
+	SortBy         SeatSortField
+	Descending     bool
+	Cursor         string
+	Skip           int
+	Limit          int
+}
+
+// seatCursor only carries the sort order and the _id of the last seat, so it does not expose seat
+// data. The sort key is read from that seat when the next page is requested.
+type seatCursor struct {
+	SortBy     SeatSortField      `json:"s"`
+	Descending bool               `json:"d"`
+	ID         primitive.ObjectID `json:"i"`
+	anchor     Seat
 }
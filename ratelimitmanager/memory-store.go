@@ -0,0 +1,69 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	sweepInterval     = time.Minute
+	bucketIdleTimeout = 15 * time.Minute
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (store *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	store.sweep(now)
+	b, ok := store.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.Burst, updatedAt: now}
+		store.buckets[key] = b
+	}
+	ratePerSecond := limit.RequestsPerMinute / 60
+	b.tokens = math.Min(limit.Burst, b.tokens+now.Sub(b.updatedAt).Seconds()*ratePerSecond)
+	b.updatedAt = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	return false, retryAfter(b.tokens, ratePerSecond), nil
+}
+
+func (store *MemoryStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < sweepInterval {
+		return
+	}
+	store.lastSweep = now
+	for key, b := range store.buckets {
+		if now.Sub(b.updatedAt) > bucketIdleTimeout {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func retryAfter(tokens, ratePerSecond float64) time.Duration {
+	seconds := math.Ceil((1 - tokens) / ratePerSecond)
+	return time.Duration(seconds) * time.Second
+}
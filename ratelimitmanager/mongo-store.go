@@ -0,0 +1,35 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+)
+
+type MongoStore struct {
+	mongoConnection *mongomanager.MongoConnection
+}
+
+func NewMongoStore(mongoConnection *mongomanager.MongoConnection) (*MongoStore, error) {
+	err := mongoConnection.EnsureRateLimitIndexes()
+	if err != nil {
+		return nil, err
+	}
+	return &MongoStore{
+		mongoConnection: mongoConnection,
+	}, nil
+}
+
+func (store *MongoStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	ratePerSecond := limit.RequestsPerMinute / 60
+	ttl := time.Duration(limit.Burst/ratePerSecond*float64(time.Second)) + time.Minute
+	bucket, err := store.mongoConnection.TakeRateLimitToken(ctx, key, limit.Burst, ratePerSecond, ttl)
+	if err != nil {
+		return false, 0, err
+	}
+	if bucket.Allowed {
+		return true, 0, nil
+	}
+	return false, retryAfter(bucket.Tokens, ratePerSecond), nil
+}
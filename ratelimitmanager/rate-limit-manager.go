@@ -0,0 +1,96 @@
+package ratelimitmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+const configFile = "./keys/rate-limits.json"
+
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+type RateLimiter struct {
+	config Config
+	store  Store
+	log    *zap.Logger
+}
+
+func InitRateLimiter(log *zap.Logger, mongoConnection *mongomanager.MongoConnection) (*RateLimiter, error) {
+	log.Info("Init rate limiter")
+	config, err := loadConfig(log)
+	if err != nil {
+		return nil, err
+	}
+	var store Store
+	switch config.Store {
+	case StoreMongo:
+		store, err = NewMongoStore(mongoConnection)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		store = NewMemoryStore()
+	}
+	log.Info("Rate limiter store selected", zap.String("store", string(config.Store)))
+	rateLimiter := &RateLimiter{
+		config: config,
+		store:  store,
+		log:    log.Named("ratelimiter"),
+	}
+	return rateLimiter, nil
+}
+
+func fileExists(filename string) bool {
+	info, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return false
+	}
+	return !info.IsDir()
+}
+
+func loadConfig(log *zap.Logger) (Config, error) {
+	if !fileExists(configFile) {
+		log.Info("No rate limit config found, using defaults.", zap.String("file", configFile))
+		return defaultConfig(), nil
+	}
+	file, err := os.Open(configFile)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+
+	config := defaultConfig()
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return Config{}, err
+	}
+	validate := validator.New()
+	err = validate.Struct(config)
+	if err != nil {
+		return Config{}, errors.New("Invalid rate limit config: " + err.Error())
+	}
+	log.Info("Rate limit config read", zap.String("file", configFile))
+	return config, nil
+}
+
+func (rateLimiter *RateLimiter) Allow(ctx context.Context, group Group, key string) (bool, time.Duration) {
+	limit, ok := rateLimiter.config.Groups[group]
+	if !ok {
+		return true, 0
+	}
+	allowed, retryAfter, err := rateLimiter.store.Take(ctx, string(group)+":"+key, limit)
+	if err != nil {
+		rateLimiter.log.Error("Error checking rate limit, allowing request", zap.String("group", string(group)), zap.Error(err))
+		return true, 0
+	}
+	return allowed, retryAfter
+}
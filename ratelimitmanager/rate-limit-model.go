@@ -0,0 +1,39 @@
+package ratelimitmanager
+
+type StoreType string
+
+const (
+	StoreMemory StoreType = "memory"
+	StoreMongo  StoreType = "mongo"
+)
+
+type Group string
+
+const (
+	GroupProduct    Group = "product"
+	GroupDashboard  Group = "dashboard"
+	GroupCheckout   Group = "checkout"
+	GroupNewsletter Group = "newsletter"
+)
+
+type Limit struct {
+	RequestsPerMinute float64 `json:"requestsPerMinute" validate:"gt=0"`
+	Burst             float64 `json:"burst" validate:"gte=1"`
+}
+
+type Config struct {
+	Store  StoreType       `json:"store" validate:"required,oneof=memory mongo"`
+	Groups map[Group]Limit `json:"groups" validate:"required,dive"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Store: StoreMemory,
+		Groups: map[Group]Limit{
+			GroupProduct:    {RequestsPerMinute: 60, Burst: 30},
+			GroupDashboard:  {RequestsPerMinute: 120, Burst: 60},
+			GroupCheckout:   {RequestsPerMinute: 20, Burst: 10},
+			GroupNewsletter: {RequestsPerMinute: 5, Burst: 5},
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package requestmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderRequestID          = "X-Request-ID"
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	ContextKeyRequestID      = "requestID"
+	ContextKeyIdempotencyKey = "idempotencyKey"
+	ContextKeyClientIP       = "clientIP"
+	maxKeyLength             = 128
+)
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+func GenerateRequestID() (string, error) {
+	idBytes := make([]byte, 16)
+	_, err := rand.Read(idBytes)
+	if err != nil {
+		return "", errors.New("failed to generate request ID")
+	}
+	return hex.EncodeToString(idBytes), nil
+}
+
+func IsValidRequestID(requestID string) bool {
+	return isValidKey(requestID)
+}
+
+func IsValidIdempotencyKey(idempotencyKey string) bool {
+	return isValidKey(idempotencyKey)
+}
+
+func isValidKey(key string) bool {
+	if key == "" || len(key) > maxKeyLength {
+		return false
+	}
+	return keyPattern.MatchString(key)
+}
+
+func GetRequestID(ctx context.Context) string {
+	return getString(ctx, ContextKeyRequestID)
+}
+
+func GetIdempotencyKey(ctx context.Context) string {
+	return getString(ctx, ContextKeyIdempotencyKey)
+}
+
+func GetClientIP(ctx context.Context) string {
+	return getString(ctx, ContextKeyClientIP)
+}
+
+func getString(ctx context.Context, key string) string {
+	if ctx == nil {
+		return ""
+	}
+	value, ok := ctx.Value(key).(string)
+	if !ok {
+		return ""
+	}
+	return value
+}
+
+func GetLogger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	requestID := GetRequestID(ctx)
+	if requestID == "" {
+		return log
+	}
+	return log.With(zap.String("requestID", requestID))
+}
@@ -0,0 +1,481 @@
+package stripemanager
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/charge"
+	"github.com/stripe/stripe-go/v82/creditnote"
+	"github.com/stripe/stripe-go/v82/invoice"
+	"github.com/stripe/stripe-go/v82/refund"
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+const (
+	datevDefaultCurrency              = "EUR"
+	datevDefaultRevenueAccount        = "8400"
+	datevDefaultReducedRevenueAccount = "8300"
+	datevDefaultReverseChargeAccount  = "8336"
+	datevDefaultTaxFreeAccount        = "8338"
+	datevDefaultForeignRevenueAccount = "8320"
+	datevDefaultDebtorAccount         = "10000"
+	datevDefaultBankAccount           = "1360"
+	datevTextLimit                    = 60
+)
+
+var zeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true, "krw": true, "mga": true,
+	"pyg": true, "rwf": true, "ugx": true, "vnd": true, "vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+var threeDecimalCurrencies = map[string]bool{
+	"bhd": true, "jod": true, "kwd": true, "omr": true, "tnd": true,
+}
+
+var accountingCSVHeader = []string{
+	"type", "id", "number", "status", "date",
+	"customerID", "customerName", "customerEMail",
+	"line1", "line2", "postalCode", "city", "country",
+	"currency", "net", "tax", "gross", "taxCategory", "reference",
+}
+
+var datevColumns = []string{
+	"Umsatz (ohne Soll/Haben-Kz)", "Soll/Haben-Kennzeichen", "WKZ Umsatz", "Kurs", "Basis-Umsatz", "WKZ Basis-Umsatz",
+	"Konto", "Gegenkonto (ohne BU-Schlüssel)", "BU-Schlüssel", "Belegdatum", "Belegfeld 1", "Belegfeld 2", "Skonto", "Buchungstext",
+}
+
+func (stripeConnection *StripeConnection) ExportAccounting(ctx context.Context, request AccountingExportRequest, w io.Writer) error {
+	err := validator.New().Struct(request)
+	if err != nil {
+		return err
+	}
+	if request.Format == AccountingExportFormatDATEV && request.From.Year() != request.To.Add(-time.Nanosecond).Year() {
+		return errors.New("a DATEV export must not span more than one fiscal year")
+	}
+	records, err := stripeConnection.GetAccountingRecords(ctx, request.From, request.To)
+	if err != nil {
+		return err
+	}
+	stripeConnection.Log.Info("Accounting records collected", zap.Int("records", len(records)), zap.Time("from", request.From), zap.Time("to", request.To))
+	if request.Format == AccountingExportFormatDATEV {
+		return WriteAccountingDATEV(w, records, request)
+	}
+	return WriteAccountingCSV(w, records, request.From.Location())
+}
+
+func (stripeConnection *StripeConnection) GetAccountingRecords(ctx context.Context, from, to time.Time) ([]AccountingRecord, error) {
+	stripe.Key = stripeConnection.Key
+	created := &stripe.RangeQueryParams{
+		GreaterThanOrEqual: from.Unix(),
+		LesserThan:         to.Unix(),
+	}
+	collectors := []func(context.Context, *stripe.RangeQueryParams) ([]AccountingRecord, error){
+		stripeConnection.accountingInvoices,
+		stripeConnection.accountingPayments,
+		stripeConnection.accountingRefunds,
+		stripeConnection.accountingCreditNotes,
+	}
+	records := []AccountingRecord{}
+	for _, collect := range collectors {
+		collected, err := collect(ctx, created)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, collected...)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date.Before(records[j].Date)
+	})
+	return records, nil
+}
+
+// accountingInvoices selects and dates invoices by their finalization. Stripe only filters invoices
+// by creation, and an invoice is finalized after it is created, so every invoice created before the
+// end of the range is listed and the range is applied to the finalization date.
+func (stripeConnection *StripeConnection) accountingInvoices(ctx context.Context, created *stripe.RangeQueryParams) ([]AccountingRecord, error) {
+	params := &stripe.InvoiceListParams{CreatedRange: &stripe.RangeQueryParams{LesserThan: created.LesserThan}}
+	params.Context = ctx
+	params.AddExpand("data.customer")
+	records := []AccountingRecord{}
+	iter := invoice.List(params)
+	for iter.Next() {
+		inv := iter.Invoice()
+		if inv.Status == stripe.InvoiceStatusDraft || inv.Status == stripe.InvoiceStatusVoid {
+			continue
+		}
+		date := invoiceFinalizedAt(inv)
+		if date < created.GreaterThanOrEqual || date >= created.LesserThan {
+			continue
+		}
+		var tax int64
+		reverseCharge := false
+		for _, totalTax := range inv.TotalTaxes {
+			tax += totalTax.Amount
+			reverseCharge = reverseCharge || totalTax.TaxabilityReason == stripe.InvoiceTotalTaxTaxabilityReasonReverseCharge
+		}
+		record := AccountingRecord{
+			Type:     AccountingRecordTypeInvoice,
+			ID:       inv.ID,
+			Number:   inv.Number,
+			Status:   string(inv.Status),
+			Date:     time.Unix(date, 0),
+			Currency: string(inv.Currency),
+			Net:      inv.TotalExcludingTax,
+			Tax:      tax,
+			Gross:    inv.Total,
+			HasTax:   true,
+		}
+		if inv.Parent != nil && inv.Parent.SubscriptionDetails != nil && inv.Parent.SubscriptionDetails.Subscription != nil {
+			record.Reference = inv.Parent.SubscriptionDetails.Subscription.ID
+		}
+		record = withCustomer(record, inv.Customer)
+		record.TaxCategory = taxCategoryOf(record, reverseCharge)
+		records = append(records, record)
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error listing invoices for accounting export", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+func invoiceFinalizedAt(inv *stripe.Invoice) int64 {
+	if inv.StatusTransitions != nil && inv.StatusTransitions.FinalizedAt != 0 {
+		return inv.StatusTransitions.FinalizedAt
+	}
+	return inv.Created
+}
+
+func (stripeConnection *StripeConnection) accountingPayments(ctx context.Context, created *stripe.RangeQueryParams) ([]AccountingRecord, error) {
+	params := &stripe.ChargeListParams{CreatedRange: created}
+	params.Context = ctx
+	params.AddExpand("data.customer")
+	records := []AccountingRecord{}
+	iter := charge.List(params)
+	for iter.Next() {
+		ch := iter.Charge()
+		if !ch.Paid || ch.Status != stripe.ChargeStatusSucceeded {
+			continue
+		}
+		record := AccountingRecord{
+			Type:     AccountingRecordTypePayment,
+			ID:       ch.ID,
+			Number:   ch.ReceiptNumber,
+			Status:   string(ch.Status),
+			Date:     time.Unix(ch.Created, 0),
+			Currency: string(ch.Currency),
+			Gross:    ch.Amount,
+		}
+		if ch.PaymentIntent != nil {
+			record.Reference = ch.PaymentIntent.ID
+		}
+		records = append(records, withCustomer(record, ch.Customer))
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error listing charges for accounting export", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+func (stripeConnection *StripeConnection) accountingRefunds(ctx context.Context, created *stripe.RangeQueryParams) ([]AccountingRecord, error) {
+	params := &stripe.RefundListParams{CreatedRange: created}
+	params.Context = ctx
+	params.AddExpand("data.charge.customer")
+	records := []AccountingRecord{}
+	iter := refund.List(params)
+	for iter.Next() {
+		re := iter.Refund()
+		if re.Status != stripe.RefundStatusSucceeded {
+			continue
+		}
+		record := AccountingRecord{
+			Type:     AccountingRecordTypeRefund,
+			ID:       re.ID,
+			Status:   string(re.Status),
+			Date:     time.Unix(re.Created, 0),
+			Currency: string(re.Currency),
+			Gross:    re.Amount,
+		}
+		var customer *stripe.Customer
+		if re.Charge != nil {
+			record.Reference = re.Charge.ID
+			customer = re.Charge.Customer
+		}
+		records = append(records, withCustomer(record, customer))
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error listing refunds for accounting export", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+func (stripeConnection *StripeConnection) accountingCreditNotes(ctx context.Context, created *stripe.RangeQueryParams) ([]AccountingRecord, error) {
+	params := &stripe.CreditNoteListParams{CreatedRange: created}
+	params.Context = ctx
+	params.AddExpand("data.customer")
+	records := []AccountingRecord{}
+	iter := creditnote.List(params)
+	for iter.Next() {
+		note := iter.CreditNote()
+		if note.Status == stripe.CreditNoteStatusVoid {
+			continue
+		}
+		var tax int64
+		reverseCharge := false
+		for _, totalTax := range note.TotalTaxes {
+			tax += totalTax.Amount
+			reverseCharge = reverseCharge || totalTax.TaxabilityReason == stripe.CreditNoteTotalTaxTaxabilityReasonReverseCharge
+		}
+		record := AccountingRecord{
+			Type:     AccountingRecordTypeCreditNote,
+			ID:       note.ID,
+			Number:   note.Number,
+			Status:   string(note.Status),
+			Date:     time.Unix(note.Created, 0),
+			Currency: string(note.Currency),
+			Net:      note.TotalExcludingTax,
+			Tax:      tax,
+			Gross:    note.Total,
+			HasTax:   true,
+		}
+		if note.Invoice != nil {
+			record.Reference = note.Invoice.ID
+		}
+		record = withCustomer(record, note.Customer)
+		record.TaxCategory = taxCategoryOf(record, reverseCharge)
+		records = append(records, record)
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error listing credit notes for accounting export", zap.Error(err))
+		return nil, err
+	}
+	return records, nil
+}
+
+func taxCategoryOf(record AccountingRecord, reverseCharge bool) AccountingTaxCategory {
+	if record.Tax == 0 {
+		if reverseCharge {
+			return AccountingTaxCategoryReverseCharge
+		}
+		return AccountingTaxCategoryTaxFree
+	}
+	if record.Address.Country != "" && record.Address.Country != sellerCountry {
+		return AccountingTaxCategoryForeign
+	}
+	if record.Net <= 0 {
+		return AccountingTaxCategoryStandard
+	}
+	switch math.Round(float64(record.Tax) * 100 / float64(record.Net)) {
+	case 19:
+		return AccountingTaxCategoryStandard
+	case 7:
+		return AccountingTaxCategoryReduced
+	default:
+		return AccountingTaxCategoryForeign
+	}
+}
+
+func withCustomer(record AccountingRecord, customer *stripe.Customer) AccountingRecord {
+	if customer == nil {
+		return record
+	}
+	billingAddress := billingAddressOf("", customer)
+	record.CustomerID = customer.ID
+	record.CustomerName = billingAddress.Name
+	record.CustomerEMail = customer.Email
+	record.Address = billingAddress.Address
+	return record
+}
+
+func WriteAccountingCSV(w io.Writer, records []AccountingRecord, location *time.Location) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(accountingCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		net, tax := "", ""
+		if record.HasTax {
+			net = formatMinorUnits(record.Net, record.Currency, ".")
+			tax = formatMinorUnits(record.Tax, record.Currency, ".")
+		}
+		line2 := ""
+		if record.Address.Line2 != nil {
+			line2 = *record.Address.Line2
+		}
+		err = writer.Write([]string{
+			string(record.Type), record.ID, record.Number, record.Status, record.Date.In(location).Format(time.DateOnly),
+			record.CustomerID, record.CustomerName, record.CustomerEMail,
+			record.Address.Line1, line2, record.Address.PostalCode, record.Address.City, record.Address.Country,
+			strings.ToUpper(record.Currency), net, tax, formatMinorUnits(record.Gross, record.Currency, "."), string(record.TaxCategory), record.Reference,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type datevAccounts struct {
+	revenue        map[AccountingTaxCategory]string
+	foreignTaxKey  string
+	debtorAccount  string
+	bankAccount    string
+	accountsLength int
+}
+
+func datevAccountsOf(options DATEVOptions) datevAccounts {
+	revenueAccount := defaultString(options.RevenueAccount, datevDefaultRevenueAccount)
+	return datevAccounts{
+		revenue: map[AccountingTaxCategory]string{
+			AccountingTaxCategoryStandard:      revenueAccount,
+			AccountingTaxCategoryReduced:       defaultString(options.ReducedRevenueAccount, datevDefaultReducedRevenueAccount),
+			AccountingTaxCategoryReverseCharge: defaultString(options.ReverseChargeAccount, datevDefaultReverseChargeAccount),
+			AccountingTaxCategoryTaxFree:       defaultString(options.TaxFreeAccount, datevDefaultTaxFreeAccount),
+			AccountingTaxCategoryForeign:       defaultString(options.ForeignRevenueAccount, datevDefaultForeignRevenueAccount),
+		},
+		foreignTaxKey:  options.ForeignTaxKey,
+		debtorAccount:  defaultString(options.DebtorAccount, datevDefaultDebtorAccount),
+		bankAccount:    defaultString(options.BankAccount, datevDefaultBankAccount),
+		accountsLength: len(revenueAccount),
+	}
+}
+
+func WriteAccountingDATEV(w io.Writer, records []AccountingRecord, request AccountingExportRequest) error {
+	options := request.DATEV
+	accounts := datevAccountsOf(options)
+	currency := strings.ToUpper(defaultString(options.Currency, datevDefaultCurrency))
+	location := request.From.Location()
+	fiscalYearStart := time.Date(request.From.Year(), time.January, 1, 0, 0, 0, 0, location)
+
+	header := []string{
+		datevText("EXTF"), "700", "21", datevText("Buchungsstapel"), "13",
+		strings.Replace(time.Now().In(location).Format("20060102150405.000"), ".", "", 1), "", datevText("SC"), datevText(""), datevText(""),
+		strconv.Itoa(options.ConsultantNumber), strconv.Itoa(options.ClientNumber),
+		fiscalYearStart.Format("20060102"), strconv.Itoa(accounts.accountsLength),
+		request.From.Format("20060102"), request.To.Add(-time.Nanosecond).In(location).Format("20060102"),
+		datevText("Stripe"), datevText(""), "1", "0", "0", datevText(currency),
+	}
+	columns := make([]string, 0, len(datevColumns))
+	for _, column := range datevColumns {
+		columns = append(columns, datevText(column))
+	}
+	lines := []string{strings.Join(header, ";"), strings.Join(columns, ";")}
+
+	for _, record := range records {
+		if strings.ToUpper(record.Currency) != currency {
+			return errors.New("DATEV export only supports records in " + currency + ", " + record.ID + " is in " + strings.ToUpper(record.Currency) + "; use the CSV export instead")
+		}
+		debitCredit, contraAccount, taxKey := datevBooking(record, accounts)
+		text := strings.TrimSpace(string(record.Type) + " " + record.CustomerName)
+		if len([]rune(text)) > datevTextLimit {
+			text = string([]rune(text)[:datevTextLimit])
+		}
+		documentField := record.Number
+		if documentField == "" {
+			documentField = record.ID
+		}
+		lines = append(lines, strings.Join([]string{
+			formatMinorUnits(absAmount(record.Gross), record.Currency, ","), datevText(debitCredit), datevText(currency), "", "", "",
+			accounts.debtorAccount, contraAccount, datevText(taxKey), record.Date.In(location).Format("0201"),
+			datevText(datevDocumentField(documentField)), datevText(""), "", datevText(text),
+		}, ";"))
+	}
+	encoder := transform.NewWriter(w, encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()))
+	_, err := io.WriteString(encoder, strings.Join(lines, "\r\n")+"\r\n")
+	if err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func datevBooking(record AccountingRecord, accounts datevAccounts) (string, string, string) {
+	taxKey := ""
+	if record.TaxCategory == AccountingTaxCategoryForeign {
+		taxKey = accounts.foreignTaxKey
+	}
+	switch record.Type {
+	case AccountingRecordTypeInvoice:
+		return "S", accounts.revenue[record.TaxCategory], taxKey
+	case AccountingRecordTypeCreditNote:
+		return "H", accounts.revenue[record.TaxCategory], taxKey
+	case AccountingRecordTypePayment:
+		return "H", accounts.bankAccount, ""
+	default:
+		return "S", accounts.bankAccount, ""
+	}
+}
+
+func datevDocumentField(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("$&%*+-/", r) {
+			builder.WriteRune(r)
+		}
+	}
+	field := builder.String()
+	if len(field) > 36 {
+		field = field[len(field)-36:]
+	}
+	return field
+}
+
+func datevText(value string) string {
+	return "\"" + strings.ReplaceAll(value, "\"", "\"\"") + "\""
+}
+
+func currencyDecimals(currency string) int {
+	currency = strings.ToLower(currency)
+	if zeroDecimalCurrencies[currency] {
+		return 0
+	}
+	if threeDecimalCurrencies[currency] {
+		return 3
+	}
+	return 2
+}
+
+func formatMinorUnits(amount int64, currency, decimalSeparator string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	decimals := currencyDecimals(currency)
+	if decimals == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+	divisor := int64(math.Pow10(decimals))
+	fraction := strconv.FormatInt(amount%divisor, 10)
+	fraction = strings.Repeat("0", decimals-len(fraction)) + fraction
+	return sign + strconv.FormatInt(amount/divisor, 10) + decimalSeparator + fraction
+}
+
+func absAmount(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
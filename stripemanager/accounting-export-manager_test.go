@@ -0,0 +1,159 @@
+package stripemanager
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stripe/stripe-go/v82"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestFormatMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount    int64
+		currency  string
+		separator string
+		want      string
+	}{
+		{amount: 12345, currency: "eur", separator: ",", want: "123,45"},
+		{amount: 5, currency: "EUR", separator: ".", want: "0.05"},
+		{amount: 100, currency: "usd", separator: ".", want: "1.00"},
+		{amount: 0, currency: "eur", separator: ",", want: "0,00"},
+		{amount: -12345, currency: "eur", separator: ",", want: "-123,45"},
+		{amount: -5, currency: "eur", separator: ",", want: "-0,05"},
+		{amount: 1234, currency: "jpy", separator: ",", want: "1234"},
+		{amount: -1234, currency: "JPY", separator: ",", want: "-1234"},
+		{amount: 12345, currency: "kwd", separator: ".", want: "12.345"},
+		{amount: 5, currency: "bhd", separator: ",", want: "0,005"},
+	}
+	for _, test := range tests {
+		got := formatMinorUnits(test.amount, test.currency, test.separator)
+		if got != test.want {
+			t.Errorf("formatMinorUnits(%d, %q, %q) = %q, want %q", test.amount, test.currency, test.separator, got, test.want)
+		}
+	}
+}
+
+func TestTaxCategoryOf(t *testing.T) {
+	tests := []struct {
+		name          string
+		record        AccountingRecord
+		reverseCharge bool
+		want          AccountingTaxCategory
+	}{
+		{name: "standard rate", record: AccountingRecord{Net: 10000, Tax: 1900, Address: Address{Country: "DE"}}, want: AccountingTaxCategoryStandard},
+		{name: "reduced rate", record: AccountingRecord{Net: 10000, Tax: 700, Address: Address{Country: "DE"}}, want: AccountingTaxCategoryReduced},
+		{name: "rounded standard rate", record: AccountingRecord{Net: 999, Tax: 190}, want: AccountingTaxCategoryStandard},
+		{name: "reverse charge", record: AccountingRecord{Net: 10000, Address: Address{Country: "FR"}}, reverseCharge: true, want: AccountingTaxCategoryReverseCharge},
+		{name: "tax free", record: AccountingRecord{Net: 10000, Address: Address{Country: "US"}}, want: AccountingTaxCategoryTaxFree},
+		{name: "foreign country", record: AccountingRecord{Net: 10000, Tax: 2000, Address: Address{Country: "FR"}}, want: AccountingTaxCategoryForeign},
+		{name: "foreign rate", record: AccountingRecord{Net: 10000, Tax: 2100, Address: Address{Country: "DE"}}, want: AccountingTaxCategoryForeign},
+		{name: "no net amount", record: AccountingRecord{Net: 0, Tax: 190, Address: Address{Country: "DE"}}, want: AccountingTaxCategoryStandard},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := taxCategoryOf(test.record, test.reverseCharge)
+			if got != test.want {
+				t.Errorf("taxCategoryOf() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInvoiceFinalizedAt(t *testing.T) {
+	created := &stripe.Invoice{Created: 100}
+	if got := invoiceFinalizedAt(created); got != 100 {
+		t.Errorf("invoiceFinalizedAt() = %d, want 100", got)
+	}
+	finalized := &stripe.Invoice{Created: 100, StatusTransitions: &stripe.InvoiceStatusTransitions{FinalizedAt: 200}}
+	if got := invoiceFinalizedAt(finalized); got != 200 {
+		t.Errorf("invoiceFinalizedAt() = %d, want 200", got)
+	}
+}
+
+func datevTestRequest() AccountingExportRequest {
+	location := time.FixedZone("CET", 3600)
+	return AccountingExportRequest{
+		From:   time.Date(2025, time.March, 1, 0, 0, 0, 0, location),
+		To:     time.Date(2025, time.April, 1, 0, 0, 0, 0, location),
+		Format: AccountingExportFormatDATEV,
+		DATEV: DATEVOptions{
+			ConsultantNumber: 1001,
+			ClientNumber:     1,
+			ForeignTaxKey:    "240",
+		},
+	}
+}
+
+func decodeDATEV(t *testing.T, data []byte) []string {
+	t.Helper()
+	decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(data, []byte("\r\n")) {
+		t.Fatal("DATEV export does not end with CRLF")
+	}
+	return strings.Split(strings.TrimSuffix(string(decoded), "\r\n"), "\r\n")
+}
+
+func TestWriteAccountingDATEV(t *testing.T) {
+	request := datevTestRequest()
+	date := time.Date(2025, time.March, 14, 12, 0, 0, 0, time.UTC)
+	records := []AccountingRecord{
+		{Type: AccountingRecordTypeInvoice, ID: "in_1", Number: "SC-0001", Date: date, CustomerName: "Müller GmbH", Currency: "eur", Net: 10000, Tax: 1900, Gross: 11900, TaxCategory: AccountingTaxCategoryStandard},
+		{Type: AccountingRecordTypeInvoice, ID: "in_2", Number: "SC-0002", Date: date, Currency: "eur", Net: 10000, Gross: 10000, TaxCategory: AccountingTaxCategoryReverseCharge},
+		{Type: AccountingRecordTypeCreditNote, ID: "cn_1", Number: "SC-0001-CN-01", Date: date, Currency: "eur", Net: -1000, Tax: -200, Gross: -1200, TaxCategory: AccountingTaxCategoryForeign},
+		{Type: AccountingRecordTypePayment, ID: "ch_1", Date: date, Currency: "eur", Gross: 11900},
+		{Type: AccountingRecordTypeRefund, ID: "re_1", Date: date, Currency: "eur", Gross: -1200},
+	}
+	var buffer bytes.Buffer
+	err := WriteAccountingDATEV(&buffer, records, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := decodeDATEV(t, buffer.Bytes())
+	if len(lines) != 2+len(records) {
+		t.Fatalf("got %d lines, want %d", len(lines), 2+len(records))
+	}
+	header := strings.Split(lines[0], ";")
+	if header[0] != `"EXTF"` || header[10] != "1001" || header[11] != "1" || header[12] != "20250101" || header[14] != "20250301" || header[15] != "20250331" || header[21] != `"EUR"` {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	tests := []struct {
+		amount, debitCredit, contraAccount, taxKey, date, document string
+	}{
+		{amount: "119,00", debitCredit: `"S"`, contraAccount: "8400", taxKey: `""`, date: "1403", document: `"SC-0001"`},
+		{amount: "100,00", debitCredit: `"S"`, contraAccount: "8336", taxKey: `""`, date: "1403", document: `"SC-0002"`},
+		{amount: "12,00", debitCredit: `"H"`, contraAccount: "8320", taxKey: `"240"`, date: "1403", document: `"SC-0001-CN-01"`},
+		{amount: "119,00", debitCredit: `"H"`, contraAccount: "1360", taxKey: `""`, date: "1403", document: `"ch1"`},
+		{amount: "12,00", debitCredit: `"S"`, contraAccount: "1360", taxKey: `""`, date: "1403", document: `"re1"`},
+	}
+	for i, test := range tests {
+		fields := strings.Split(lines[2+i], ";")
+		got := []string{fields[0], fields[1], fields[7], fields[8], fields[9], fields[10]}
+		want := []string{test.amount, test.debitCredit, test.contraAccount, test.taxKey, test.date, test.document}
+		if strings.Join(got, ";") != strings.Join(want, ";") {
+			t.Errorf("booking %d = %q, want %q", i, got, want)
+		}
+		if fields[6] != "10000" {
+			t.Errorf("booking %d debtor account = %q, want 10000", i, fields[6])
+		}
+	}
+	if !strings.Contains(lines[2], `"invoice Müller GmbH"`) {
+		t.Errorf("booking text not encoded as Windows-1252: %q", lines[2])
+	}
+}
+
+func TestWriteAccountingDATEVRejectsOtherCurrencies(t *testing.T) {
+	records := []AccountingRecord{
+		{Type: AccountingRecordTypeInvoice, ID: "in_1", Date: time.Now(), Currency: "usd", Gross: 100, TaxCategory: AccountingTaxCategoryTaxFree},
+	}
+	var buffer bytes.Buffer
+	err := WriteAccountingDATEV(&buffer, records, datevTestRequest())
+	if err == nil {
+		t.Fatal("expected an error for a record in another currency")
+	}
+}
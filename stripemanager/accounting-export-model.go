@@ -0,0 +1,69 @@
+package stripemanager
+
+import "time"
+
+type AccountingExportFormat string
+
+const (
+	AccountingExportFormatCSV   AccountingExportFormat = "csv"
+	AccountingExportFormatDATEV AccountingExportFormat = "datev"
+)
+
+type AccountingRecordType string
+
+const (
+	AccountingRecordTypeInvoice    AccountingRecordType = "invoice"
+	AccountingRecordTypePayment    AccountingRecordType = "payment"
+	AccountingRecordTypeRefund     AccountingRecordType = "refund"
+	AccountingRecordTypeCreditNote AccountingRecordType = "creditNote"
+)
+
+type AccountingTaxCategory string
+
+const (
+	AccountingTaxCategoryStandard      AccountingTaxCategory = "standard"
+	AccountingTaxCategoryReduced       AccountingTaxCategory = "reduced"
+	AccountingTaxCategoryReverseCharge AccountingTaxCategory = "reverseCharge"
+	AccountingTaxCategoryTaxFree       AccountingTaxCategory = "taxFree"
+	AccountingTaxCategoryForeign       AccountingTaxCategory = "foreign"
+)
+
+type AccountingExportRequest struct {
+	From   time.Time              `validate:"required"`
+	To     time.Time              `validate:"required,gtfield=From"`
+	Format AccountingExportFormat `validate:"required,oneof=csv datev"`
+	DATEV  DATEVOptions
+}
+
+type DATEVOptions struct {
+	ConsultantNumber      int    `validate:"omitempty,gte=1000,lte=9999999"`
+	ClientNumber          int    `validate:"omitempty,gte=1,lte=99999"`
+	Currency              string `validate:"omitempty,len=3,alpha"`
+	RevenueAccount        string `validate:"omitempty,numeric"`
+	ReducedRevenueAccount string `validate:"omitempty,numeric"`
+	ReverseChargeAccount  string `validate:"omitempty,numeric"`
+	TaxFreeAccount        string `validate:"omitempty,numeric"`
+	ForeignRevenueAccount string `validate:"omitempty,numeric"`
+	ForeignTaxKey         string `validate:"omitempty,numeric,max=4"`
+	DebtorAccount         string `validate:"omitempty,numeric"`
+	BankAccount           string `validate:"omitempty,numeric"`
+}
+
+type AccountingRecord struct {
+	Type          AccountingRecordType
+	ID            string
+	Number        string
+	Status        string
+	Date          time.Time
+	CustomerID    string
+	CustomerName  string
+	CustomerEMail string
+	Address       Address
+	Currency      string
+	Net           int64
+	Tax           int64
+	Gross         int64
+	HasTax        bool
+	TaxCategory   AccountingTaxCategory
+	Reference     string
+}
@@ -0,0 +1,79 @@
+package stripemanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/subscription"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) audit(c context.Context, tokenDetails firebasemanager.TokenDetails, action mongomanager.AuditAction, subscriptionID, target string, before, after interface{}) {
+	paymentHandler.auditFrom(c, tokenDetails, requestmanager.GetClientIP(c), action, subscriptionID, target, before, after)
+}
+
+func (paymentHandler *PaymentHandler) auditFrom(c context.Context, tokenDetails firebasemanager.TokenDetails, ip string, action mongomanager.AuditAction, subscriptionID, target string, before, after interface{}) {
+	entry := mongomanager.AuditEntry{
+		SubscriptionID: subscriptionID,
+		ActorUID:       tokenDetails.UID,
+		ActorEMail:     tokenDetails.EMail,
+		Action:         action,
+		Target:         target,
+		Before:         mongomanager.ToAuditDocument(before),
+		After:          mongomanager.ToAuditDocument(after),
+		IP:             ip,
+		RequestID:      requestmanager.GetRequestID(c),
+		Timestamp:      time.Now(),
+	}
+	err := paymentHandler.MongoConnection.CreateAuditEntry(c, entry)
+	if err != nil {
+		paymentHandler.log(c).Error("Error writing audit entry", zap.String("action", string(action)), zap.String("subscriptionID", subscriptionID), zap.Error(err))
+	}
+}
+
+// auditCustomer records a change of a Stripe customer on every subscription the customer pays for,
+// because the audit log is kept per subscription.
+func (paymentHandler *PaymentHandler) auditCustomer(c context.Context, tokenDetails firebasemanager.TokenDetails, ip string, action mongomanager.AuditAction, customerID string, before, after interface{}) {
+	stripe.Key = paymentHandler.StripeConnection.Key
+	params := &stripe.SubscriptionListParams{
+		Customer: stripe.String(customerID),
+		Status:   stripe.String("all"),
+	}
+	params.Context = c
+	i := subscription.List(params)
+	for i.Next() {
+		paymentHandler.auditFrom(c, tokenDetails, ip, action, i.Subscription().ID, customerID, before, after)
+	}
+	if err := i.Err(); err != nil {
+		paymentHandler.log(c).Error("Error listing subscriptions for audit entry", zap.String("action", string(action)), zap.String("customerID", customerID), zap.Error(err))
+	}
+}
+
+func (paymentHandler *PaymentHandler) ListAuditLog(c context.Context, tokenDetails firebasemanager.TokenDetails, request AuditLogRequest) (AuditLogReply, error) {
+	entries, err := paymentHandler.MongoConnection.FindAuditEntries(c, mongomanager.AuditQuery{
+		SubscriptionID: request.SubscriptionID,
+		Action:         request.Action,
+		Limit:          request.PageSize + 1,
+		Cursor:         request.Cursor,
+	})
+	if err != nil {
+		return AuditLogReply{}, err
+	}
+	hasMore := len(entries) > request.PageSize
+	nextCursor := ""
+	if hasMore {
+		entries = entries[:request.PageSize]
+		nextCursor = entries[len(entries)-1].ID.Hex()
+	}
+	reply := AuditLogReply{
+		SubscriptionID: request.SubscriptionID,
+		Entries:        entries,
+		HasMore:        hasMore,
+		NextCursor:     nextCursor,
+	}
+	return reply, nil
+}
@@ -0,0 +1,17 @@
+package stripemanager
+
+import "github.com/scalecloud/scalecloud.de-api/mongomanager"
+
+type AuditLogRequest struct {
+	SubscriptionID string                   `json:"subscriptionID" validate:"required"`
+	PageSize       int                      `json:"pageSize" validate:"gte=1,lte=100"`
+	Action         mongomanager.AuditAction `json:"action"`
+	Cursor         string                   `json:"cursor"`
+}
+
+type AuditLogReply struct {
+	SubscriptionID string                    `json:"subscriptionID" validate:"required"`
+	Entries        []mongomanager.AuditEntry `json:"entries" validate:"required"`
+	HasMore        bool                      `json:"hasMore"`
+	NextCursor     string                    `json:"nextCursor,omitempty"`
+}
@@ -6,15 +6,13 @@ import (
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/customer"
+	"go.uber.org/zap"
 )
 
 func (paymentHandler *PaymentHandler) GetBillingAddress(c context.Context, tokenDetails firebasemanager.TokenDetails, request BillingAddressRequest) (BillingAddressReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleBilling})
-	if err != nil {
-		return BillingAddressReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 
 	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
@@ -28,35 +26,40 @@ func (paymentHandler *PaymentHandler) GetBillingAddress(c context.Context, token
 	if subscription.Customer.ID == "" {
 		return BillingAddressReply{}, errors.New("subscription customer ID is empty")
 	}
-	customer, err := GetCustomerByID(c, subscription.Customer.ID)
+	customer, err := getCustomerWithTaxIDs(c, subscription.Customer.ID)
 	if err != nil {
 		return BillingAddressReply{}, err
 	}
 
-	address := Address{
-		City:       customer.Address.City,
-		Country:    customer.Address.Country,
-		Line1:      customer.Address.Line1,
-		Line2:      &customer.Address.Line2,
-		PostalCode: customer.Address.PostalCode,
-	}
+	reply := billingAddressOf(request.SubscriptionID, customer)
 
-	reply := BillingAddressReply{
-		SubscriptionID: request.SubscriptionID,
+	return reply, nil
+
+}
+
+func billingAddressOf(subscriptionID string, customer *stripe.Customer) BillingAddressReply {
+	address := Address{}
+	if customer.Address != nil {
+		address = Address{
+			City:       customer.Address.City,
+			Country:    customer.Address.Country,
+			Line1:      customer.Address.Line1,
+			Line2:      &customer.Address.Line2,
+			PostalCode: customer.Address.PostalCode,
+		}
+	}
+	return BillingAddressReply{
+		SubscriptionID: subscriptionID,
 		Name:           customer.Name,
+		CompanyName:    customer.Metadata[metadataCompanyName],
 		Address:        address,
 		Phone:          customer.Phone,
+		TaxIDs:         taxIDDetailsOf(customer),
+		TaxExempt:      TaxExempt(customer.TaxExempt),
 	}
-
-	return reply, nil
-
 }
 
 func (paymentHandler *PaymentHandler) UpdateBillingAddress(c context.Context, tokenDetails firebasemanager.TokenDetails, request UpdateBillingAddressRequest) (UpdateBillingAddressReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleBilling})
-	if err != nil {
-		return UpdateBillingAddressReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 
 	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
@@ -70,6 +73,36 @@ func (paymentHandler *PaymentHandler) UpdateBillingAddress(c context.Context, to
 	if subscription.Customer.ID == "" {
 		return UpdateBillingAddressReply{}, errors.New("subscription customer ID is empty")
 	}
+	customerBefore, err := getCustomerWithTaxIDs(c, subscription.Customer.ID)
+	if err != nil {
+		return UpdateBillingAddressReply{}, err
+	}
+	customerAfter, err := paymentHandler.updateCustomerBillingAddress(c, customerBefore, request)
+	if err != nil {
+		return UpdateBillingAddressReply{}, err
+	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionBillingAddressUpdate, request.SubscriptionID, subscription.Customer.ID, billingAddressOf(request.SubscriptionID, customerBefore), billingAddressOf(request.SubscriptionID, customerAfter))
+
+	reply := UpdateBillingAddressReply{
+		SubscriptionID: request.SubscriptionID,
+	}
+
+	return reply, nil
+
+}
+
+func (paymentHandler *PaymentHandler) updateCustomerBillingAddress(c context.Context, customerBefore *stripe.Customer, request UpdateBillingAddressRequest) (*stripe.Customer, error) {
+	changes := taxIDChanges{kept: customerTaxIDs(customerBefore)}
+	if request.TaxIDs != nil {
+		taxIDs, err := validateTaxIDs(request.TaxIDs)
+		if err != nil {
+			return nil, err
+		}
+		changes, err = paymentHandler.createTaxIDs(c, customerBefore, taxIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	params := &stripe.CustomerParams{
 		Name: stripe.String(request.Name),
@@ -80,18 +113,48 @@ func (paymentHandler *PaymentHandler) UpdateBillingAddress(c context.Context, to
 			Line2:      stripe.String(*request.Address.Line2),
 			PostalCode: stripe.String(request.Address.PostalCode),
 		},
-		Phone: stripe.String(request.Phone),
+		Phone:     stripe.String(request.Phone),
+		TaxExempt: stripe.String(string(taxExemptFor(request.Address.Country, changes.result()))),
+		Tax: &stripe.CustomerTaxParams{
+			ValidateLocation: stripe.String("immediately"),
+		},
+	}
+	if ip := requestmanager.GetClientIP(c); ip != "" {
+		params.Tax.IPAddress = stripe.String(ip)
+	}
+	if request.CompanyName != nil {
+		params.AddMetadata(metadataCompanyName, *request.CompanyName)
+		if *request.CompanyName == "" {
+			params.AddExtra("invoice_settings[custom_fields]", "")
+		} else {
+			params.InvoiceSettings = &stripe.CustomerInvoiceSettingsParams{
+				CustomFields: []*stripe.CustomerInvoiceSettingsCustomFieldParams{
+					{
+						Name:  stripe.String(invoiceFieldCompany),
+						Value: stripe.String(*request.CompanyName),
+					},
+				},
+			}
+		}
 	}
+	params.AddExpand(customerTaxIDsExpand)
+	withRequest(c, &params.Params, "update-billing-address")
 
-	_, err = customer.Update(subscription.Customer.ID, params)
+	customerAfter, err := customer.Update(customerBefore.ID, params)
 	if err != nil {
-		return UpdateBillingAddressReply{}, err
+		paymentHandler.log(c).Warn("Error updating billing address", zap.String("customerID", customerBefore.ID), zap.Error(err))
+		paymentHandler.deleteTaxIDs(c, customerBefore.ID, changes.created)
+		return nil, err
 	}
-
-	reply := UpdateBillingAddressReply{
-		SubscriptionID: request.SubscriptionID,
+	deleted := paymentHandler.deleteTaxIDs(c, customerBefore.ID, changes.stale)
+	if customerAfter.TaxIDs != nil {
+		remaining := []*stripe.TaxID{}
+		for _, taxID := range customerAfter.TaxIDs.Data {
+			if !deleted[taxID.ID] {
+				remaining = append(remaining, taxID)
+			}
+		}
+		customerAfter.TaxIDs.Data = remaining
 	}
-
-	return reply, nil
-
+	return customerAfter, nil
 }
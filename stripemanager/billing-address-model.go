@@ -12,18 +12,31 @@ type BillingAddressRequest struct {
 	SubscriptionID string `json:"subscriptionID" validate:"required"`
 }
 
+func (request BillingAddressRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
+}
+
 type BillingAddressReply struct {
-	SubscriptionID string  `json:"subscriptionID" validate:"required"`
-	Name           string  `json:"name" validate:"required"`
-	Address        Address `json:"address" validate:"required"`
-	Phone          string  `json:"phone" validate:"required"`
+	SubscriptionID string        `json:"subscriptionID" validate:"required"`
+	Name           string        `json:"name" validate:"required"`
+	CompanyName    string        `json:"companyName"`
+	Address        Address       `json:"address" validate:"required"`
+	Phone          string        `json:"phone" validate:"required"`
+	TaxIDs         []TaxIDDetail `json:"taxIDs"`
+	TaxExempt      TaxExempt     `json:"taxExempt"`
 }
 
 type UpdateBillingAddressRequest struct {
 	SubscriptionID string  `json:"subscriptionID" validate:"required"`
 	Name           string  `json:"name" validate:"required"`
+	CompanyName    *string `json:"companyName" validate:"omitempty,max=140"`
 	Address        Address `json:"address" validate:"required"`
 	Phone          string  `json:"phone" validate:"required"`
+	TaxIDs         []TaxID `json:"taxIDs" validate:"omitempty,max=5,dive"`
+}
+
+func (request UpdateBillingAddressRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
 }
 
 type UpdateBillingAddressReply struct {
@@ -21,6 +21,7 @@ func (paymentHandler *PaymentHandler) GetBillingPortal(c context.Context, tokenD
 		Customer:  stripe.String(customerID),
 		ReturnURL: stripe.String("https://www.scalecloud.de/dashboard"),
 	}
+	withRequest(c, &params.Params, "billing-portal-session")
 	session, err := session.New(params)
 	if err != nil {
 		return BillingPortalReply{}, err
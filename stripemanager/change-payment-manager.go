@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/customer"
 	"github.com/stripe/stripe-go/v82/paymentmethod"
@@ -21,6 +23,17 @@ const (
 	ChangePayment      SetupIntentMeta = "changePayment"
 )
 
+// The change is applied by the setup_intent.succeeded webhook, so the actor travels in the metadata.
+const (
+	setupIntentActorUID    = "actorUID"
+	setupIntentActorEMail  = "actorEMail"
+	setupIntentActorIP     = "actorIP"
+	setupIntentActorStripe = "stripe"
+)
+
+// GetChangePaymentSetupIntent is not audited: the SetupIntent changes nothing until it is confirmed,
+// and ChangePaymentDefault and ChangeCustomerAddress audit the change then.
+
 func (paymentHandler *PaymentHandler) GetChangePaymentSetupIntent(c context.Context, tokenDetails firebasemanager.TokenDetails) (ChangePaymentReply, error) {
 	customerID, err := paymentHandler.GetCustomerIDByUID(c, tokenDetails.UID)
 	if err != nil {
@@ -33,6 +46,10 @@ func (paymentHandler *PaymentHandler) GetChangePaymentSetupIntent(c context.Cont
 	}
 
 	params.AddMetadata(string(SetupIntentMetaKey), string(ChangePayment))
+	params.AddMetadata(setupIntentActorUID, tokenDetails.UID)
+	params.AddMetadata(setupIntentActorEMail, tokenDetails.EMail)
+	params.AddMetadata(setupIntentActorIP, requestmanager.GetClientIP(c))
+	withRequest(c, &params.Params, "change-payment-setup-intent")
 
 	si, err := setupintent.New(params)
 	if err != nil {
@@ -61,38 +78,56 @@ func (paymentHandler *PaymentHandler) ChangePaymentDefault(c context.Context, se
 			DefaultPaymentMethod: stripe.String(setupIntent.PaymentMethod.ID),
 		},
 	}
+	withRequest(c, &params.Params, "change-payment-default")
 	result, err := customer.Update(cus.ID, params)
 	if err != nil {
 		return err
 	}
-	paymentHandler.Log.Info("Customer updated", zap.Any("Customer", result.ID))
-	err = paymentHandler.detachPaymentMethodsButDefault(setupIntent)
+	paymentHandler.log(c).Info("Customer updated", zap.Any("Customer", result.ID))
+	detached, err := paymentHandler.detachPaymentMethodsButDefault(c, setupIntent)
+	actor, ip := setupIntentActor(setupIntent)
+	paymentHandler.auditCustomer(c, actor, ip, mongomanager.AuditActionPaymentMethodChange, cus.ID, map[string]interface{}{"paymentMethods": detached}, map[string]interface{}{"paymentMethod": setupIntent.PaymentMethod.ID})
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (paymentHandler *PaymentHandler) detachPaymentMethodsButDefault(setupIntent stripe.SetupIntent) error {
+func setupIntentActor(setupIntent stripe.SetupIntent) (firebasemanager.TokenDetails, string) {
+	actor := firebasemanager.TokenDetails{
+		UID:   setupIntent.Metadata[setupIntentActorUID],
+		EMail: setupIntent.Metadata[setupIntentActorEMail],
+	}
+	if actor.UID == "" {
+		actor.UID = setupIntentActorStripe
+	}
+	return actor, setupIntent.Metadata[setupIntentActorIP]
+}
+
+func (paymentHandler *PaymentHandler) detachPaymentMethodsButDefault(c context.Context, setupIntent stripe.SetupIntent) ([]string, error) {
 	stripe.Key = paymentHandler.StripeConnection.Key
 	params := &stripe.PaymentMethodListParams{
 		Customer: stripe.String(setupIntent.Customer.ID),
 	}
 	i := paymentmethod.List(params)
+	var detached []string
 	for i.Next() {
 		pm := i.PaymentMethod()
 		if pm.ID != setupIntent.PaymentMethod.ID {
+			detachParams := &stripe.PaymentMethodDetachParams{}
+			withRequest(c, &detachParams.Params, "detach-payment-method-"+pm.ID)
 			pmDetached, err := paymentmethod.Detach(
 				pm.ID,
-				nil,
+				detachParams,
 			)
 			if err != nil {
-				return err
+				return detached, err
 			}
-			paymentHandler.Log.Info("PaymentMethod detached", zap.Any("PaymentMethod", pmDetached.ID))
+			paymentHandler.log(c).Info("PaymentMethod detached", zap.Any("PaymentMethod", pmDetached.ID))
+			detached = append(detached, pmDetached.ID)
 		}
 	}
-	return nil
+	return detached, nil
 }
 
 func (paymentHandler *PaymentHandler) ChangeCustomerAddress(c context.Context, setupIntent stripe.SetupIntent) error {
@@ -126,10 +161,17 @@ func (paymentHandler *PaymentHandler) ChangeCustomerAddress(c context.Context, s
 			Country:    stripe.String(address.Country),
 		},
 	}
+	customerBefore, err := getCustomerWithTaxIDs(c, cus.ID)
+	if err != nil {
+		return err
+	}
+	withRequest(c, &params.Params, "change-customer-address")
 	updatedCustomer, err := customer.Update(cus.ID, params)
 	if err != nil {
 		return err
 	}
-	paymentHandler.Log.Info("Customer address updated", zap.Any("Customer", updatedCustomer.ID))
+	paymentHandler.log(c).Info("Customer address updated", zap.Any("Customer", updatedCustomer.ID))
+	actor, ip := setupIntentActor(setupIntent)
+	paymentHandler.auditCustomer(c, actor, ip, mongomanager.AuditActionBillingAddressUpdate, cus.ID, billingAddressOf("", customerBefore), billingAddressOf("", updatedCustomer))
 	return nil
 }
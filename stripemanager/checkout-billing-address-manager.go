@@ -0,0 +1,61 @@
+package stripemanager
+
+import (
+	"context"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/stripe/stripe-go/v82"
+)
+
+func (paymentHandler *PaymentHandler) GetCheckoutBillingAddress(c context.Context, tokenDetails firebasemanager.TokenDetails) (CheckoutBillingAddressReply, error) {
+	customerID, err := paymentHandler.searchOrCreateCustomer(c, tokenDetails.EMail, tokenDetails.UID)
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	stripe.Key = paymentHandler.StripeConnection.Key
+	cus, err := getCustomerWithTaxIDs(c, customerID)
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	return paymentHandler.checkoutBillingAddressOf(c, cus)
+}
+
+func (paymentHandler *PaymentHandler) UpdateCheckoutBillingAddress(c context.Context, tokenDetails firebasemanager.TokenDetails, request UpdateCheckoutBillingAddressRequest) (CheckoutBillingAddressReply, error) {
+	customerID, err := paymentHandler.searchOrCreateCustomer(c, tokenDetails.EMail, tokenDetails.UID)
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	stripe.Key = paymentHandler.StripeConnection.Key
+	customerBefore, err := getCustomerWithTaxIDs(c, customerID)
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	customerAfter, err := paymentHandler.updateCustomerBillingAddress(c, customerBefore, UpdateBillingAddressRequest{
+		Name:        request.Name,
+		CompanyName: request.CompanyName,
+		Address:     request.Address,
+		Phone:       request.Phone,
+		TaxIDs:      request.TaxIDs,
+	})
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	return paymentHandler.checkoutBillingAddressOf(c, customerAfter)
+}
+
+func (paymentHandler *PaymentHandler) checkoutBillingAddressOf(c context.Context, cus *stripe.Customer) (CheckoutBillingAddressReply, error) {
+	hasValidBillingAddress, err := paymentHandler.StripeConnection.hasTaxableBillingAddress(c, cus.ID)
+	if err != nil {
+		return CheckoutBillingAddressReply{}, err
+	}
+	billingAddress := billingAddressOf("", cus)
+	return CheckoutBillingAddressReply{
+		Name:                   billingAddress.Name,
+		CompanyName:            billingAddress.CompanyName,
+		Address:                billingAddress.Address,
+		Phone:                  billingAddress.Phone,
+		TaxIDs:                 billingAddress.TaxIDs,
+		TaxExempt:              billingAddress.TaxExempt,
+		HasValidBillingAddress: &hasValidBillingAddress,
+	}, nil
+}
@@ -0,0 +1,19 @@
+package stripemanager
+
+type CheckoutBillingAddressReply struct {
+	Name                   string        `json:"name"`
+	CompanyName            string        `json:"companyName"`
+	Address                Address       `json:"address"`
+	Phone                  string        `json:"phone"`
+	TaxIDs                 []TaxIDDetail `json:"taxIDs"`
+	TaxExempt              TaxExempt     `json:"taxExempt"`
+	HasValidBillingAddress *bool         `json:"has_valid_billing_address" validate:"required"`
+}
+
+type UpdateCheckoutBillingAddressRequest struct {
+	Name        string  `json:"name" validate:"required"`
+	CompanyName *string `json:"companyName" validate:"omitempty,max=140"`
+	Address     Address `json:"address" validate:"required"`
+	Phone       string  `json:"phone" validate:"required"`
+	TaxIDs      []TaxID `json:"taxIDs" validate:"omitempty,max=5,dive"`
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
@@ -22,10 +23,21 @@ func (paymentHandler *PaymentHandler) CreateCheckoutSubscription(c context.Conte
 	if err != nil {
 		return CheckoutCreateSubscriptionReply{}, err
 	}
+	err = requireTaxBehavior(price)
+	if err != nil {
+		return CheckoutCreateSubscriptionReply{}, err
+	}
 	cus, err := paymentHandler.GetCustomerByUID(c, tokenDetails.UID)
 	if err != nil {
 		return CheckoutCreateSubscriptionReply{}, err
 	}
+	hasValidBillingAddress, err := paymentHandler.StripeConnection.hasTaxableBillingAddress(c, cus.ID)
+	if err != nil {
+		return CheckoutCreateSubscriptionReply{}, err
+	}
+	if !hasValidBillingAddress {
+		return CheckoutCreateSubscriptionReply{}, ErrBillingAddressRequired
+	}
 	paymentMethod, err := paymentHandler.StripeConnection.GetDefaultPaymentMethod(c, cus)
 	if err != nil {
 		return CheckoutCreateSubscriptionReply{}, err
@@ -39,6 +51,13 @@ func (paymentHandler *PaymentHandler) CreateCheckoutSubscription(c context.Conte
 	if err != nil {
 		return CheckoutCreateSubscriptionReply{}, err
 	}
+	var promo *stripe.PromotionCode
+	if checkoutCreateSubscriptionRequest.PromotionCode != "" {
+		promo, err = paymentHandler.StripeConnection.getPromotionCode(c, checkoutCreateSubscriptionRequest.PromotionCode, product, price, checkoutCreateSubscriptionRequest.Quantity, cus.ID)
+		if err != nil {
+			return CheckoutCreateSubscriptionReply{}, err
+		}
+	}
 	subscriptionParams := &stripe.SubscriptionParams{
 		Customer: stripe.String(cus.ID),
 		Items: []*stripe.SubscriptionItemsParams{
@@ -47,26 +66,37 @@ func (paymentHandler *PaymentHandler) CreateCheckoutSubscription(c context.Conte
 				Quantity: stripe.Int64(checkoutCreateSubscriptionRequest.Quantity),
 			},
 		},
+		AutomaticTax: automaticTax(),
 	}
 	if iTrialPeriodDays > 0 {
 		subscriptionParams.TrialPeriodDays = stripe.Int64(iTrialPeriodDays)
 	}
+	if promo != nil {
+		subscriptionParams.Discounts = []*stripe.SubscriptionDiscountParams{
+			{
+				PromotionCode: stripe.String(promo.ID),
+			},
+		}
+	}
+	withRequest(c, &subscriptionParams.Params, "create-subscription")
 	sub, err := subscription.New(subscriptionParams)
 	if err != nil {
-		paymentHandler.Log.Error("Error creating subscription", zap.Error(err))
+		paymentHandler.log(c).Error("Error creating subscription", zap.Error(err))
 		return CheckoutCreateSubscriptionReply{}, err
 	}
-	paymentHandler.Log.Info("Subscription created.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
+	paymentHandler.log(c).Info("Subscription created.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
 	if sub.Status == stripe.SubscriptionStatusActive || sub.Status == stripe.SubscriptionStatusTrialing {
-		paymentHandler.Log.Info("Subscription is valid.")
+		paymentHandler.log(c).Info("Subscription is valid.")
 		createSeat(c, sub, tokenDetails, paymentHandler)
 	} else if sub.Status == stripe.SubscriptionStatusIncomplete || sub.Status == stripe.SubscriptionStatusIncompleteExpired {
-		paymentHandler.Log.Warn("First payment did not work. Subscription is incomplete.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
+		paymentHandler.log(c).Warn("First payment did not work. Subscription is incomplete.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
 	} else {
-		paymentHandler.Log.Error("Subscription should not get this status. Canceling subscription.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
-		sub, err = subscription.Cancel(sub.ID, nil)
+		paymentHandler.log(c).Error("Subscription should not get this status. Canceling subscription.", zap.Any("subscriptionID", sub.ID), zap.Any("status", sub.Status))
+		cancelParams := &stripe.SubscriptionCancelParams{}
+		withRequest(c, &cancelParams.Params, "cancel-subscription-"+sub.ID)
+		sub, err = subscription.Cancel(sub.ID, cancelParams)
 		if err != nil {
-			paymentHandler.Log.Error("Error canceling subscription", zap.Error(err))
+			paymentHandler.log(c).Error("Error canceling subscription", zap.Error(err))
 		}
 	}
 	checkoutSubscriptionModel := CheckoutCreateSubscriptionReply{
@@ -76,16 +106,20 @@ func (paymentHandler *PaymentHandler) CreateCheckoutSubscription(c context.Conte
 		EMail:          tokenDetails.EMail,
 		TrialEnd:       sub.TrialEnd,
 	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSubscriptionCreate, sub.ID, sub.ID, nil, checkoutSubscriptionModel)
 	return checkoutSubscriptionModel, nil
 }
 
 func createSeat(c context.Context, sub *stripe.Subscription, tokenDetails firebasemanager.TokenDetails, paymentHandler *PaymentHandler) {
 	emailVerified := false
+	now := time.Now()
 	seat := mongomanager.Seat{
 		SubscriptionID: sub.ID,
 		UID:            tokenDetails.UID,
 		EMail:          tokenDetails.EMail,
 		EMailVerified:  &emailVerified,
+		InvitedAt:      &now,
+		JoinedAt:       &now,
 		Roles: []mongomanager.Role{
 			mongomanager.RoleOwner,
 			mongomanager.RoleAdministrator,
@@ -95,7 +129,7 @@ func createSeat(c context.Context, sub *stripe.Subscription, tokenDetails fireba
 	}
 	err := paymentHandler.MongoConnection.CreateSeat(c, seat)
 	if err != nil {
-		paymentHandler.Log.Error("Error creating seat", zap.Error(err))
+		paymentHandler.log(c).Error("Error creating seat", zap.Error(err))
 	}
 }
 
@@ -105,6 +139,10 @@ func (paymentHandler *PaymentHandler) GetCheckoutProduct(c context.Context, toke
 	if err != nil {
 		return CheckoutProductReply{}, err
 	}
+	err = requireTaxBehavior(price)
+	if err != nil {
+		return CheckoutProductReply{}, err
+	}
 	currency := strings.ToUpper(string(price.Currency))
 	product, err := paymentHandler.StripeConnection.GetProduct(c, checkoutProductRequest.ProductID)
 	if err != nil {
@@ -130,6 +168,29 @@ func (paymentHandler *PaymentHandler) GetCheckoutProduct(c context.Context, toke
 	if err != nil {
 		return CheckoutProductReply{}, err
 	}
+	hasValidBillingAddress, err := paymentHandler.StripeConnection.hasTaxableBillingAddress(c, cus.ID)
+	if err != nil {
+		return CheckoutProductReply{}, err
+	}
+	taxCustomerID := ""
+	if hasValidBillingAddress {
+		taxCustomerID = cus.ID
+	}
+	totals, err := paymentHandler.StripeConnection.calculatePriceTotals(c, price, price.UnitAmount, product.ID, taxCustomerID)
+	if err != nil {
+		return CheckoutProductReply{}, err
+	}
+	var discount *CheckoutDiscount
+	if checkoutProductRequest.PromotionCode != "" {
+		promo, err := paymentHandler.StripeConnection.getPromotionCode(c, checkoutProductRequest.PromotionCode, product, price, 1, cus.ID)
+		if err != nil {
+			return CheckoutProductReply{}, err
+		}
+		discount, err = paymentHandler.StripeConnection.previewCheckoutDiscount(c, promo, price, product.ID, taxCustomerID)
+		if err != nil {
+			return CheckoutProductReply{}, err
+		}
+	}
 	metaDataProduct := product.Metadata
 	if metaDataProduct == nil {
 		return CheckoutProductReply{}, errors.New("product metadata not found")
@@ -140,7 +201,7 @@ func (paymentHandler *PaymentHandler) GetCheckoutProduct(c context.Context, toke
 	}
 	iStorageAmount, err := strconv.ParseInt(storageAmount, 10, 64)
 	if err != nil {
-		paymentHandler.Log.Warn("Error converting storageAmount to int", zap.Error(err))
+		paymentHandler.log(c).Warn("Error converting storageAmount to int", zap.Error(err))
 		return CheckoutProductReply{}, errors.New("error converting storageAmount")
 	}
 	storageUnit, ok := metaDataProduct["storageUnit"]
@@ -152,14 +213,18 @@ func (paymentHandler *PaymentHandler) GetCheckoutProduct(c context.Context, toke
 		return CheckoutProductReply{}, errors.New("Product name not found for priceID: " + price.ID)
 	}
 	checkoutProductReply := CheckoutProductReply{
-		ProductID:             checkoutProductRequest.ProductID,
-		Name:                  productName,
-		StorageAmount:         iStorageAmount,
-		StorageUnit:           storageUnit,
-		TrialDays:             iTrialPeriodDays,
-		PricePerMonth:         price.UnitAmount,
-		Currency:              currency,
-		HasValidPaymentMethod: &hasValidPaymentMethod,
+		ProductID:                 checkoutProductRequest.ProductID,
+		Name:                      productName,
+		StorageAmount:             iStorageAmount,
+		StorageUnit:               storageUnit,
+		TrialDays:                 iTrialPeriodDays,
+		PricePerMonth:             price.UnitAmount,
+		PricePerMonthExclusiveTax: totals.exclusiveTax,
+		PricePerMonthInclusiveTax: totals.inclusiveTax,
+		Currency:                  currency,
+		HasValidPaymentMethod:     &hasValidPaymentMethod,
+		HasValidBillingAddress:    &hasValidBillingAddress,
+		Discount:                  discount,
 	}
 	return checkoutProductReply, nil
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/stripe/stripe-go/v82/setupintent"
 )
 
+// CreateCheckoutSetupIntent is not audited: no subscription exists yet, and CreateCheckoutSubscription
+// records subscription.create once it does.
 func (paymentHandler *PaymentHandler) CreateCheckoutSetupIntent(c context.Context, tokenDetails firebasemanager.TokenDetails, checkoutSetupIntentRequest CheckoutSetupIntentRequest) (CheckoutSetupIntentReply, error) {
 	customerID, err := paymentHandler.searchOrCreateCustomer(c, tokenDetails.EMail, tokenDetails.UID)
 	if err != nil {
@@ -22,6 +24,7 @@ func (paymentHandler *PaymentHandler) CreateCheckoutSetupIntent(c context.Contex
 	setupIntentParam := &stripe.SetupIntentParams{
 		Customer: stripe.String(customerID),
 	}
+	withRequest(c, &setupIntentParam.Params, "checkout-setup-intent")
 	setupIntent, err := setupintent.New(setupIntentParam)
 	if err != nil {
 		return CheckoutSetupIntentReply{}, err
@@ -1,8 +1,9 @@
 package stripemanager
 
 type CheckoutCreateSubscriptionRequest struct {
-	ProductID string `json:"productID" binding:"required"`
-	Quantity  int64  `json:"quantity" binding:"required"`
+	ProductID     string `json:"productID" binding:"required"`
+	Quantity      int64  `json:"quantity" binding:"required"`
+	PromotionCode string `json:"promotionCode" binding:"omitempty,max=64"`
 }
 
 type CheckoutCreateSubscriptionReply struct {
@@ -14,16 +15,21 @@ type CheckoutCreateSubscriptionReply struct {
 }
 
 type CheckoutProductRequest struct {
-	ProductID string `json:"productID" binding:"required"`
+	ProductID     string `json:"productID" binding:"required"`
+	PromotionCode string `json:"promotionCode" binding:"omitempty,max=64"`
 }
 
 type CheckoutProductReply struct {
-	ProductID             string `json:"productID" validate:"required"`
-	Name                  string `json:"name" validate:"required"`
-	StorageAmount         int64  `json:"storageAmount" validate:"required"`
-	StorageUnit           string `json:"storageUnit" validate:"required"`
-	TrialDays             int64  `json:"trialDays" validate:"required"`
-	PricePerMonth         int64  `json:"pricePerMonth" validate:"required"`
-	Currency              string `json:"currency" validate:"required"`
-	HasValidPaymentMethod *bool  `json:"has_valid_payment_method" validate:"required"`
+	ProductID                 string            `json:"productID" validate:"required"`
+	Name                      string            `json:"name" validate:"required"`
+	StorageAmount             int64             `json:"storageAmount" validate:"required"`
+	StorageUnit               string            `json:"storageUnit" validate:"required"`
+	TrialDays                 int64             `json:"trialDays" validate:"required"`
+	PricePerMonth             int64             `json:"pricePerMonth" validate:"required"`
+	PricePerMonthExclusiveTax int64             `json:"pricePerMonthExclusiveTax" validate:"required"`
+	PricePerMonthInclusiveTax int64             `json:"pricePerMonthInclusiveTax" validate:"required"`
+	Currency                  string            `json:"currency" validate:"required"`
+	HasValidPaymentMethod     *bool             `json:"has_valid_payment_method" validate:"required"`
+	HasValidBillingAddress    *bool             `json:"has_valid_billing_address" validate:"required"`
+	Discount                  *CheckoutDiscount `json:"discount,omitempty"`
 }
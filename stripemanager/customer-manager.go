@@ -72,9 +72,18 @@ func (stripeConnection *StripeConnection) CreateCustomer(ctx context.Context, em
 	params := &stripe.CustomerParams{
 		Email: stripe.String(email),
 	}
+	withRequest(ctx, &params.Params, "create-customer")
 	newCustomer, err := customer.New(params)
 	if err != nil {
 		return nil, err
 	}
 	return newCustomer, nil
 }
+
+func (stripeConnection *StripeConnection) DeleteCustomer(ctx context.Context, customerID string) error {
+	stripe.Key = stripeConnection.Key
+	params := &stripe.CustomerParams{}
+	withRequest(ctx, &params.Params, "delete-customer")
+	_, err := customer.Del(customerID, params)
+	return err
+}
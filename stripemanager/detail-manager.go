@@ -6,22 +6,17 @@ import (
 	"strconv"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
-	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/stripe/stripe-go/v82"
 	"go.uber.org/zap"
 )
 
 func (paymentHandler *PaymentHandler) GetSubscriptionDetailByID(c context.Context, tokenDetails firebasemanager.TokenDetails, subscriptionID string) (SubscriptionDetailReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, subscriptionID, []mongomanager.Role{mongomanager.RoleBilling, mongomanager.RoleUser, mongomanager.RoleAdministrator})
-	if err != nil {
-		return SubscriptionDetailReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
-	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, subscriptionID)
+	subscription, err := paymentHandler.StripeConnection.getSubscriptionWithDiscounts(c, subscriptionID)
 	if err != nil {
 		return SubscriptionDetailReply{}, errors.New("subscription not found")
 	}
-	paymentHandler.Log.Debug("subscription", zap.Any("subscription", subscription))
+	paymentHandler.log(c).Debug("subscription", zap.Any("subscription", subscription))
 	subscriptionDetailReply, err := paymentHandler.StripeConnection.mapSubscriptionItemToSubscriptionDetail(c, subscription)
 	if err != nil {
 		return SubscriptionDetailReply{}, err
@@ -30,16 +25,12 @@ func (paymentHandler *PaymentHandler) GetSubscriptionDetailByID(c context.Contex
 }
 
 func (paymentHandler *PaymentHandler) GetCancelState(c context.Context, tokenDetails firebasemanager.TokenDetails, subscriptionID string) (CancelStateReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, subscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
-	if err != nil {
-		return CancelStateReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, subscriptionID)
 	if err != nil {
 		return CancelStateReply{}, errors.New("subscription not found")
 	}
-	paymentHandler.Log.Debug("subscription", zap.Any("subscription", subscription))
+	paymentHandler.log(c).Debug("subscription", zap.Any("subscription", subscription))
 	return CancelStateReply{
 		SubscriptionID:    subscription.ID,
 		CancelAtPeriodEnd: &subscription.CancelAtPeriodEnd,
@@ -97,6 +88,8 @@ func (stripeConnection *StripeConnection) mapSubscriptionItemToSubscriptionDetai
 
 	reply.TrialEnd = subscription.TrialEnd
 
+	reply.Discounts = activeDiscounts(subscription)
+
 	if len(subscription.Items.Data) > 0 {
 		reply.CurrentPeriodEnd = subscription.Items.Data[0].CurrentPeriodEnd
 	} else {
@@ -0,0 +1,169 @@
+package stripemanager
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/invoice"
+	"go.uber.org/zap"
+)
+
+const invoicePDFTimeout = 30 * time.Second
+
+var invoicePDFClient = &http.Client{Timeout: invoicePDFTimeout}
+
+func invoicePDFPath(subscriptionID, invoiceID string) string {
+	return "/v1/subscriptions/" + subscriptionID + "/invoices/" + invoiceID + "/pdf"
+}
+
+func (paymentHandler *PaymentHandler) GetInvoicePDF(c context.Context, tokenDetails firebasemanager.TokenDetails, request InvoicePDFRequest) (io.ReadCloser, string, error) {
+	stripe.Key = paymentHandler.StripeConnection.Key
+	inv, err := invoice.Get(request.InvoiceID, nil)
+	if err != nil {
+		paymentHandler.log(c).Warn("Error getting invoice", zap.String("invoiceID", request.InvoiceID), zap.Error(err))
+		return nil, "", errors.New("invoice not found")
+	}
+	if invoiceSubscriptionID(inv) != request.SubscriptionID {
+		paymentHandler.log(c).Warn("Invoice does not belong to subscription", zap.String("invoiceID", request.InvoiceID), zap.String("subscriptionID", request.SubscriptionID))
+		return nil, "", errors.New("invoice not found")
+	}
+	if inv.InvoicePDF == "" {
+		return nil, "", errors.New("invoice has no PDF yet")
+	}
+	body, err := fetchInvoicePDF(c, inv.InvoicePDF)
+	if err != nil {
+		paymentHandler.log(c).Error("Error downloading invoice PDF", zap.String("invoiceID", inv.ID), zap.Error(err))
+		return nil, "", errors.New("error downloading invoice PDF")
+	}
+	return body, invoiceFileName(inv), nil
+}
+
+type InvoiceArchive struct {
+	*os.File
+	Size int64
+}
+
+func (archive *InvoiceArchive) Close() error {
+	err := archive.File.Close()
+	removeErr := os.Remove(archive.Name())
+	if err != nil {
+		return err
+	}
+	return removeErr
+}
+
+func (paymentHandler *PaymentHandler) GetInvoiceArchive(c context.Context, tokenDetails firebasemanager.TokenDetails, request InvoiceArchiveRequest) (*InvoiceArchive, error) {
+	stripe.Key = paymentHandler.StripeConnection.Key
+	start := time.Date(request.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	params := &stripe.InvoiceListParams{
+		Subscription: stripe.String(request.SubscriptionID),
+		CreatedRange: &stripe.RangeQueryParams{
+			GreaterThanOrEqual: start.Unix(),
+			LesserThan:         start.AddDate(1, 0, 0).Unix(),
+		},
+	}
+	params.Limit = stripe.Int64(100)
+	var invoices []*stripe.Invoice
+	iter := invoice.List(params)
+	for iter.Next() {
+		inv := iter.Invoice()
+		if inv.InvoicePDF != "" {
+			invoices = append(invoices, inv)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		paymentHandler.log(c).Error("Error listing invoices", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+		return nil, errors.New("error listing invoices")
+	}
+	if len(invoices) == 0 {
+		return nil, errors.New("no invoices found for " + strconv.Itoa(request.Year))
+	}
+	file, err := os.CreateTemp("", "invoices-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	archive := &InvoiceArchive{File: file}
+	err = writeInvoiceArchive(c, archive, invoices)
+	if err != nil {
+		archive.Close()
+		paymentHandler.log(c).Error("Error archiving invoices", zap.String("subscriptionID", request.SubscriptionID), zap.Int("year", request.Year), zap.Error(err))
+		return nil, errors.New("error downloading invoice PDFs")
+	}
+	return archive, nil
+}
+
+func writeInvoiceArchive(c context.Context, archive *InvoiceArchive, invoices []*stripe.Invoice) error {
+	zipWriter := zip.NewWriter(archive.File)
+	for _, inv := range invoices {
+		err := addInvoiceToArchive(c, zipWriter, inv)
+		if err != nil {
+			return err
+		}
+	}
+	err := zipWriter.Close()
+	if err != nil {
+		return err
+	}
+	archive.Size, err = archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = archive.Seek(0, io.SeekStart)
+	return err
+}
+
+func addInvoiceToArchive(c context.Context, zipWriter *zip.Writer, inv *stripe.Invoice) error {
+	body, err := fetchInvoicePDF(c, inv.InvoicePDF)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	entry, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     invoiceFileName(inv),
+		Method:   zip.Store,
+		Modified: time.Unix(inv.Created, 0),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, body)
+	return err
+}
+
+func fetchInvoicePDF(c context.Context, url string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(c, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := invoicePDFClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, errors.New("unexpected status " + response.Status)
+	}
+	return response.Body, nil
+}
+
+func invoiceSubscriptionID(inv *stripe.Invoice) string {
+	if inv.Parent == nil || inv.Parent.SubscriptionDetails == nil || inv.Parent.SubscriptionDetails.Subscription == nil {
+		return ""
+	}
+	return inv.Parent.SubscriptionDetails.Subscription.ID
+}
+
+func invoiceFileName(inv *stripe.Invoice) string {
+	if inv.Number != "" {
+		return inv.Number + ".pdf"
+	}
+	return inv.ID + ".pdf"
+}
@@ -0,0 +1,11 @@
+package stripemanager
+
+type InvoicePDFRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+	InvoiceID      string `json:"invoiceID" validate:"required"`
+}
+
+type InvoiceArchiveRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+	Year           int    `json:"year" validate:"gte=2000,lte=2100"`
+}
@@ -9,7 +9,7 @@ type Invoice struct {
 	Total          int64                `json:"total" validate:"required"`
 	Currency       string               `json:"currency" validate:"required"`
 	Status         stripe.InvoiceStatus `json:"status" validate:"required"`
-	InvoicePDF     string               `json:"invoice_pdf" validate:"required"`
+	InvoicePDF     string               `json:"invoice_pdf" validate:"omitempty"`
 }
 
 type ListInvoicesRequest struct {
@@ -19,8 +19,15 @@ type ListInvoicesRequest struct {
 	EndingBefore   string `json:"endingBefore"`
 }
 
+func (request ListInvoicesRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
+}
+
 type ListInvoicesReply struct {
 	SubscriptionID string    `json:"subscriptionID" validate:"required"`
 	Invoices       []Invoice `json:"invoices" validate:"required"`
-	TotalResults   int64     `json:"totalResults" validate:"gte=1"`
+	HasMore        bool      `json:"hasMore"`
+	NextCursor     string    `json:"nextCursor,omitempty"`
+	PrevCursor     string    `json:"prevCursor,omitempty"`
+	TotalResults   *int64    `json:"totalResults,omitempty" validate:"omitempty,gte=0"`
 }
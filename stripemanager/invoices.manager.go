@@ -3,38 +3,48 @@ package stripemanager
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
-	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/invoice"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
 )
 
+const invoiceCountSeedTimeout = 2 * time.Minute
+
+var invoiceCountSeeding sync.Map
+
 func (paymentHandler *PaymentHandler) GetSubscriptionInvoices(c context.Context, tokenDetails firebasemanager.TokenDetails, request ListInvoicesRequest) (ListInvoicesReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleBilling})
-	if err != nil {
-		return ListInvoicesReply{}, err
-	}
-	totalResults, err := CountTotalInvoices(request.SubscriptionID)
-	if err != nil {
-		return ListInvoicesReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 	params := &stripe.InvoiceListParams{
 		Subscription: stripe.String(request.SubscriptionID),
 	}
 	params.Limit = stripe.Int64(int64(request.PageSize))
-	if request.EndingBefore != "" {
+	params.Single = true
+	backward := request.EndingBefore != ""
+	if backward {
 		params.EndingBefore = stripe.String(request.EndingBefore)
 	} else if request.StartingAfter != "" {
 		params.StartingAfter = stripe.String(request.StartingAfter)
 	}
-	invoiceList := invoice.List(params).InvoiceList()
+	iter := invoice.List(params)
+	if err := iter.Err(); err != nil {
+		paymentHandler.log(c).Error("Error listing invoices", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+		return ListInvoicesReply{}, errors.New("error listing invoices")
+	}
+	invoiceList := iter.InvoiceList()
 	if invoiceList == nil {
 		return ListInvoicesReply{}, errors.New("no invoices found")
 	}
-	var invoices []Invoice
+	invoices := []Invoice{}
 	for _, inv := range invoiceList.Data {
+		invoicePDF := ""
+		if inv.InvoicePDF != "" {
+			invoicePDF = invoicePDFPath(request.SubscriptionID, inv.ID)
+		}
 		invoices = append(invoices, Invoice{
 			InvoiceID:      inv.ID,
 			SubscriptionID: inv.Customer.ID,
@@ -42,38 +52,101 @@ func (paymentHandler *PaymentHandler) GetSubscriptionInvoices(c context.Context,
 			Total:          inv.Total,
 			Currency:       string(inv.Currency),
 			Status:         inv.Status,
-			InvoicePDF:     inv.InvoicePDF,
+			InvoicePDF:     invoicePDF,
 		})
 	}
 	reply := ListInvoicesReply{
 		SubscriptionID: request.SubscriptionID,
 		Invoices:       invoices,
-		TotalResults:   totalResults,
+		TotalResults:   paymentHandler.cachedInvoiceCount(c, request.SubscriptionID),
+	}
+	if len(invoices) > 0 {
+		first, last := invoices[0].InvoiceID, invoices[len(invoices)-1].InvoiceID
+		// Stripe reports has_more in the direction of the request, the opposite direction is known from the cursor.
+		if backward {
+			reply.NextCursor = last
+			if invoiceList.HasMore {
+				reply.PrevCursor = first
+			}
+		} else {
+			if invoiceList.HasMore {
+				reply.NextCursor = last
+			}
+			if request.StartingAfter != "" {
+				reply.PrevCursor = first
+			}
+		}
 	}
+	reply.HasMore = reply.NextCursor != ""
 	return reply, nil
 }
 
-func CountTotalInvoices(subscriptionID string) (int64, error) {
-	var totalResults int64
-	params := &stripe.InvoiceListParams{
-		Subscription: stripe.String(subscriptionID),
+func (paymentHandler *PaymentHandler) cachedInvoiceCount(c context.Context, subscriptionID string) *int64 {
+	invoiceCount, err := paymentHandler.MongoConnection.GetInvoiceCount(c, subscriptionID)
+	if err == nil && invoiceCount.Seeded {
+		count := invoiceCount.Count()
+		return &count
 	}
-	params.Limit = stripe.Int64(100) // Use a larger limit to reduce the number of API calls
+	if err == nil || errors.Is(err, mongo.ErrNoDocuments) {
+		paymentHandler.seedInvoiceCount(subscriptionID)
+	} else {
+		paymentHandler.log(c).Warn("Error reading cached invoice count", zap.String("subscriptionID", subscriptionID), zap.Error(err))
+	}
+	return nil
+}
 
-	for {
-		iter := invoice.List(params)
-		for iter.Next() {
-			totalResults++
-		}
-		if err := iter.Err(); err != nil {
-			return 0, err
+func (paymentHandler *PaymentHandler) seedInvoiceCount(subscriptionID string) {
+	if _, running := invoiceCountSeeding.LoadOrStore(subscriptionID, true); running {
+		return
+	}
+	go func() {
+		defer invoiceCountSeeding.Delete(subscriptionID)
+		ctx, cancel := context.WithTimeout(context.Background(), invoiceCountSeedTimeout)
+		defer cancel()
+		invoiceIDs, err := paymentHandler.ListInvoiceIDs(ctx, subscriptionID)
+		if err != nil {
+			paymentHandler.Log.Warn("Error listing invoices", zap.String("subscriptionID", subscriptionID), zap.Error(err))
+			return
 		}
-		if iter.Meta().HasMore {
-			params.StartingAfter = stripe.String(iter.Invoice().ID)
-		} else {
-			break
+		err = paymentHandler.MongoConnection.SeedInvoiceCount(ctx, subscriptionID, invoiceIDs)
+		if err != nil {
+			paymentHandler.Log.Warn("Error caching invoice count", zap.String("subscriptionID", subscriptionID), zap.Error(err))
 		}
+	}()
+}
+
+func (paymentHandler *PaymentHandler) ListInvoiceIDs(ctx context.Context, subscriptionID string) ([]string, error) {
+	stripe.Key = paymentHandler.StripeConnection.Key
+	params := &stripe.InvoiceListParams{
+		Subscription: stripe.String(subscriptionID),
 	}
+	params.Context = ctx
+	params.Limit = stripe.Int64(100)
+	invoiceIDs := []string{}
+	iter := invoice.List(params)
+	for iter.Next() {
+		invoiceIDs = append(invoiceIDs, iter.Invoice().ID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return invoiceIDs, nil
+}
 
-	return totalResults, nil
+func (paymentHandler *PaymentHandler) InvoiceCreated(c context.Context, inv stripe.Invoice) error {
+	subscriptionID := invoiceSubscriptionID(&inv)
+	if subscriptionID == "" {
+		paymentHandler.log(c).Debug("Invoice does not belong to a subscription", zap.String("invoiceID", inv.ID))
+		return nil
+	}
+	return paymentHandler.MongoConnection.AddInvoiceToCount(c, subscriptionID, inv.ID)
+}
+
+func (paymentHandler *PaymentHandler) InvoiceDeleted(c context.Context, inv stripe.Invoice) error {
+	subscriptionID := invoiceSubscriptionID(&inv)
+	if subscriptionID == "" {
+		paymentHandler.log(c).Debug("Invoice does not belong to a subscription", zap.String("invoiceID", inv.ID))
+		return nil
+	}
+	return paymentHandler.MongoConnection.RemoveInvoiceFromCount(c, subscriptionID, inv.ID)
 }
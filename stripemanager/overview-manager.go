@@ -6,34 +6,75 @@ import (
 	"strconv"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/subscription"
 	"go.uber.org/zap"
 )
 
 func (paymentHandler *PaymentHandler) GetSubscriptionsOverview(c context.Context, tokenDetails firebasemanager.TokenDetails) (subscriptionOverview []SubscriptionOverviewReply, err error) {
-	paymentHandler.Log.Warn("Change implementation to not use GetCustomerIDByUID for permission check")
-	customerID, err := paymentHandler.GetCustomerIDByUID(c, tokenDetails.UID)
+	seats, err := paymentHandler.MongoConnection.GetSeatsOfUser(c, tokenDetails.UID)
 	if err != nil {
 		return []SubscriptionOverviewReply{}, err
 	}
+	rolesBySubscription := make(map[string][]mongomanager.Role, len(seats))
+	for _, seat := range seats {
+		rolesBySubscription[seat.SubscriptionID] = seat.Roles
+	}
 	subscriptions := []SubscriptionOverviewReply{}
+	listed := make(map[string]bool)
 	stripe.Key = paymentHandler.StripeConnection.Key
-	params := &stripe.SubscriptionListParams{
-		Customer: stripe.String(customerID),
+	isCustomer, err := paymentHandler.existsCustomerByUID(c, tokenDetails.UID)
+	if err != nil {
+		return []SubscriptionOverviewReply{}, err
+	}
+	if isCustomer {
+		customerID, err := paymentHandler.GetCustomerIDByUID(c, tokenDetails.UID)
+		if err != nil {
+			return []SubscriptionOverviewReply{}, err
+		}
+		params := &stripe.SubscriptionListParams{
+			Customer: stripe.String(customerID),
+		}
+		iter := subscription.List(params)
+		for iter.Next() {
+			subscription := iter.Subscription()
+			paymentHandler.log(c).Debug("Subscription", zap.Any("subscription", subscription.Customer.ID))
+			subscriptionOverview, err := paymentHandler.StripeConnection.mapSubscriptionToSubscriptionOverview(c, subscription)
+			if err != nil {
+				return []SubscriptionOverviewReply{}, errors.New("subscription not found")
+			}
+			subscriptionOverview.Roles = rolesBySubscription[subscription.ID]
+			subscriptionOverview.PayingOwner = true
+			subscriptions = append(subscriptions, subscriptionOverview)
+			listed[subscription.ID] = true
+		}
+		if err := iter.Err(); err != nil {
+			paymentHandler.log(c).Error("Error listing subscriptions for customer", zap.Error(err))
+			return []SubscriptionOverviewReply{}, errors.New("error listing subscriptions")
+		}
+	} else {
+		paymentHandler.log(c).Debug("Caller is not a Stripe customer, listing seats only", zap.String("uid", tokenDetails.UID))
 	}
-	iter := subscription.List(params)
-	for iter.Next() {
-		subscription := iter.Subscription()
-		paymentHandler.Log.Debug("Subscription", zap.Any("subscription", subscription.Customer.ID))
+	for _, seat := range seats {
+		if listed[seat.SubscriptionID] {
+			continue
+		}
+		subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, seat.SubscriptionID)
+		if err != nil {
+			paymentHandler.log(c).Warn("Seat references a subscription that could not be loaded", zap.String("subscriptionID", seat.SubscriptionID), zap.Error(err))
+			continue
+		}
 		subscriptionOverview, err := paymentHandler.StripeConnection.mapSubscriptionToSubscriptionOverview(c, subscription)
 		if err != nil {
 			return []SubscriptionOverviewReply{}, errors.New("subscription not found")
 		}
+		subscriptionOverview.Roles = seat.Roles
 		subscriptions = append(subscriptions, subscriptionOverview)
+		listed[seat.SubscriptionID] = true
 	}
 	if len(subscriptions) == 0 {
-		paymentHandler.Log.Warn("customer with no subscriptions found", zap.String("customerID", customerID))
+		paymentHandler.log(c).Warn("user with no subscriptions found", zap.String("uid", tokenDetails.UID))
 		return []SubscriptionOverviewReply{}, errors.New("no subscriptions found")
 	}
 	return subscriptions, nil
@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
+	"html"
 
+	"github.com/scalecloud/scalecloud.de-api/emailmanager"
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/stripe/stripe-go/v82"
@@ -14,42 +15,26 @@ import (
 	"go.uber.org/zap"
 )
 
-func (paymentHandler *PaymentHandler) handleOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, seatUpdateRequest mongomanager.Seat) error {
-	if !isSeatUpdateOwnerTransfer(seatUpdateRequest) {
-		return nil
-	}
-	ownerSeat, err := paymentHandler.MongoConnection.GetOwnerSeat(c, seatUpdateRequest.SubscriptionID)
-	if err != nil {
-		return err
-	}
-	if isOwnerSeatUpdate(ownerSeat, seatUpdateRequest) {
-		return nil
-	}
-	err = hasOwnerTriggeredOwnerTransfer(tokenDetails, ownerSeat)
-	if err != nil {
-		return err
-	}
-	err = paymentHandler.isSeatDestinationVerified(c, seatUpdateRequest)
-	if err != nil {
-		return err
-	}
-	err = paymentHandler.isSeatDestinationStripeCustomer(c, seatUpdateRequest)
+func (paymentHandler *PaymentHandler) validateOwnerTransfer(c context.Context, ownerSeat, destinationSeat mongomanager.Seat) error {
+	err := isSeatDestinationVerified(destinationSeat)
 	if err != nil {
 		return err
 	}
-	err = paymentHandler.hasCustomerOnlyOneActiveSubscription(c, ownerSeat)
+	err = paymentHandler.isSeatDestinationStripeCustomer(c, destinationSeat)
 	if err != nil {
 		return err
 	}
-	err = paymentHandler.handleStripeOwnerTransfer(c, tokenDetails, seatUpdateRequest, ownerSeat)
-	if err != nil {
-		return err
-	}
-	return nil
+	return paymentHandler.hasCustomerOnlyOneActiveSubscription(c, ownerSeat)
 }
 
-func isSeatUpdateOwnerTransfer(seatUpdateRequest mongomanager.Seat) bool {
-	return mongomanager.ContainsRole(seatUpdateRequest, []mongomanager.Role{mongomanager.RoleOwner})
+func withoutRole(roles []mongomanager.Role, removed mongomanager.Role) []mongomanager.Role {
+	var filteredRoles []mongomanager.Role
+	for _, role := range roles {
+		if role != removed {
+			filteredRoles = append(filteredRoles, role)
+		}
+	}
+	return filteredRoles
 }
 
 func hasOwnerTriggeredOwnerTransfer(tokenDetails firebasemanager.TokenDetails, ownerSeat mongomanager.Seat) error {
@@ -59,27 +44,17 @@ func hasOwnerTriggeredOwnerTransfer(tokenDetails firebasemanager.TokenDetails, o
 	return nil
 }
 
-func isOwnerSeatUpdate(ownerSeat mongomanager.Seat, seatUpdateRequest mongomanager.Seat) bool {
-	return mongomanager.ContainsRole(seatUpdateRequest, []mongomanager.Role{mongomanager.RoleOwner}) &&
-		ownerSeat.UID == seatUpdateRequest.UID
-
-}
-
-func (paymentHandler *PaymentHandler) isSeatDestinationVerified(c context.Context, seatUpdateRequest mongomanager.Seat) error {
-	seatCustomerDestination, err := paymentHandler.MongoConnection.GetSeat(c, seatUpdateRequest.SubscriptionID, seatUpdateRequest.UID)
-	if err != nil {
-		return err
-	}
-	if seatCustomerDestination.EMailVerified == nil || !*seatCustomerDestination.EMailVerified {
+func isSeatDestinationVerified(destinationSeat mongomanager.Seat) error {
+	if destinationSeat.EMailVerified == nil || !*destinationSeat.EMailVerified {
 		return errors.New("new owner's E-Mail is not verified")
 	}
 	return nil
 }
 
-func (paymentHandler *PaymentHandler) isSeatDestinationStripeCustomer(c context.Context, seatUpdateRequest mongomanager.Seat) error {
-	exists, err := paymentHandler.existsCustomerByUID(c, seatUpdateRequest.UID)
+func (paymentHandler *PaymentHandler) isSeatDestinationStripeCustomer(c context.Context, destinationSeat mongomanager.Seat) error {
+	exists, err := paymentHandler.existsCustomerByUID(c, destinationSeat.UID)
 	if err != nil {
-		paymentHandler.Log.Error("Error checking if customer exists by UID", zap.Error(err))
+		paymentHandler.log(c).Error("Error checking if customer exists by UID", zap.Error(err))
 		return errors.New("error checking if customer exists by UID")
 	}
 	if exists {
@@ -91,7 +66,7 @@ func (paymentHandler *PaymentHandler) isSeatDestinationStripeCustomer(c context.
 func (paymentHandler *PaymentHandler) hasCustomerOnlyOneActiveSubscription(c context.Context, ownerSeat mongomanager.Seat) error {
 	ownerCustomerID, err := paymentHandler.GetCustomerIDByUID(c, ownerSeat.UID)
 	if err != nil {
-		paymentHandler.Log.Error("Error retrieving customerID by UID", zap.Error(err))
+		paymentHandler.log(c).Error("Error retrieving customerID by UID", zap.Error(err))
 		return errors.New("could not retrieve customerID by UID")
 	}
 	stripe.Key = paymentHandler.StripeConnection.Key
@@ -107,26 +82,26 @@ func (paymentHandler *PaymentHandler) hasCustomerOnlyOneActiveSubscription(c con
 		case stripe.SubscriptionStatusActive:
 			activeSubscriptionsCount++
 		case stripe.SubscriptionStatusIncompleteExpired:
-			paymentHandler.Log.Info("Incomplete expired subscriptions are ignored", zap.String("status", string(sub.Status)))
+			paymentHandler.log(c).Info("Incomplete expired subscriptions are ignored", zap.String("status", string(sub.Status)))
 		default:
-			return paymentHandler.handleSubscriptionStatusError(sub.Status)
+			return paymentHandler.handleSubscriptionStatusError(c, sub.Status)
 		}
 	}
 
 	if err := i.Err(); err != nil {
-		paymentHandler.Log.Error("Error listing subscriptions for customer", zap.Error(err))
+		paymentHandler.log(c).Error("Error listing subscriptions for customer", zap.Error(err))
 		return errors.New("error listing subscriptions for customer")
 	}
 
 	if activeSubscriptionsCount != 1 {
-		paymentHandler.Log.Error("Customer does not have exactly one active subscription", zap.Int("activeSubscriptionsCount", activeSubscriptionsCount))
+		paymentHandler.log(c).Error("Customer does not have exactly one active subscription", zap.Int("activeSubscriptionsCount", activeSubscriptionsCount))
 		return errors.New("ownership cannot be transferred if the customer has more than one active subscription, please contact support")
 	}
 
 	return nil
 }
 
-func (paymentHandler *PaymentHandler) handleSubscriptionStatusError(status stripe.SubscriptionStatus) error {
+func (paymentHandler *PaymentHandler) handleSubscriptionStatusError(c context.Context, status stripe.SubscriptionStatus) error {
 	errorMessages := map[stripe.SubscriptionStatus]string{
 		stripe.SubscriptionStatusCanceled:   "ownership cannot be transferred if the subscription is canceled",
 		stripe.SubscriptionStatusIncomplete: "ownership cannot be transferred if the subscription is incomplete",
@@ -140,61 +115,98 @@ func (paymentHandler *PaymentHandler) handleSubscriptionStatusError(status strip
 		return errors.New(msg)
 	}
 
-	paymentHandler.Log.Error("Unhandled subscription status", zap.String("status", string(status)))
+	paymentHandler.log(c).Error("Unhandled subscription status", zap.String("status", string(status)))
 	return errors.New("ownership cannot be transferred, please contact support")
 }
 
-func (paymentHandler *PaymentHandler) handleStripeOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, seatUpdateRequest, ownerSeat mongomanager.Seat) error {
-	paymentHandler.Log.Info("Owner transfer initiated", zap.Any("seatUpdateRequest", seatUpdateRequest))
-	customerSourceID, err := paymentHandler.GetCustomerIDByUID(c, tokenDetails.UID)
+func (paymentHandler *PaymentHandler) handleStripeOwnerTransfer(c context.Context, seatUpdateRequest, ownerSeat mongomanager.Seat) error {
+	paymentHandler.log(c).Info("Owner transfer initiated", zap.Any("seatUpdateRequest", seatUpdateRequest))
+	customerSourceID, err := paymentHandler.GetCustomerIDByUID(c, ownerSeat.UID)
 	if err != nil {
 		return err
 	}
-	err = paymentHandler.updateCustomerEMailToNewOwner(ownerSeat, seatUpdateRequest, customerSourceID)
+	err = paymentHandler.updateCustomerEMail(c, customerSourceID, ownerSeat, seatUpdateRequest, "transfer-ownership")
 	if err != nil {
 		return err
 	}
-	paymentHandler.removeSourceCustomerOwner(c, ownerSeat)
-	paymentHandler.sendConfirmationMail()
+	err = paymentHandler.MongoConnection.WithTransaction(c, func(ctx context.Context) error {
+		err := paymentHandler.MongoConnection.TransferUser(ctx, ownerSeat.UID, seatUpdateRequest.UID)
+		if err != nil {
+			return err
+		}
+		err = paymentHandler.removeSourceCustomerOwner(ctx, ownerSeat)
+		if err != nil {
+			return err
+		}
+		return paymentHandler.MongoConnection.AddSeatRole(ctx, seatUpdateRequest.SubscriptionID, seatUpdateRequest.UID, mongomanager.RoleOwner)
+	})
+	if err != nil {
+		rollbackErr := paymentHandler.updateCustomerEMail(c, customerSourceID, seatUpdateRequest, ownerSeat, "transfer-ownership-rollback")
+		if rollbackErr != nil {
+			paymentHandler.log(c).Error("Error rolling back customer E-Mail after failed owner transfer", zap.String("subscriptionID", ownerSeat.SubscriptionID), zap.Error(rollbackErr))
+		}
+		return err
+	}
 
+	paymentHandler.log(c).Info("Owner transfer completed", zap.String("subscriptionID", ownerSeat.SubscriptionID), zap.String("sourceUID", ownerSeat.UID), zap.String("destinationUID", seatUpdateRequest.UID))
+	paymentHandler.sendConfirmationMail(c, ownerSeat, seatUpdateRequest)
 	return nil
 }
 
-func (paymentHandler *PaymentHandler) updateCustomerEMailToNewOwner(ownerSeat, seatUpdateRequest mongomanager.Seat, customerSourceID string) error {
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
+func (paymentHandler *PaymentHandler) updateCustomerEMail(c context.Context, customerID string, sourceSeat, destinationSeat mongomanager.Seat, operation string) error {
 	params := &stripe.CustomerParams{
-		Email: stripe.String(seatUpdateRequest.EMail),
+		Email: stripe.String(destinationSeat.EMail),
 		Metadata: map[string]string{
-			fmt.Sprintf("transfer_ownership_%s", timestamp): fmt.Sprintf("Ownership was transferred from %s to %s for subscription %s", ownerSeat.EMail, seatUpdateRequest.EMail, ownerSeat.SubscriptionID),
+			"last_ownership_transfer": fmt.Sprintf("Ownership was transferred from %s to %s for subscription %s", sourceSeat.EMail, destinationSeat.EMail, sourceSeat.SubscriptionID),
 		},
 	}
-	_, err := customer.Update(customerSourceID, params)
+	withRequest(c, &params.Params, operation)
+	stripe.Key = paymentHandler.StripeConnection.Key
+	_, err := customer.Update(customerID, params)
 	if err != nil {
-		paymentHandler.Log.Error("Error updating customer", zap.Error(err))
+		paymentHandler.log(c).Error("Error updating customer", zap.Error(err))
 		return errors.New("error updating customer")
 	}
-	paymentHandler.Log.Error("implement update user in MongoDB")
 	return nil
 }
 
 func (paymentHandler *PaymentHandler) removeSourceCustomerOwner(c context.Context, sourceSeat mongomanager.Seat) error {
-	var filteredRoles []mongomanager.Role
-	for _, role := range sourceSeat.Roles {
-		if role != mongomanager.RoleOwner {
-			filteredRoles = append(filteredRoles, role)
-		}
-	}
-	sourceSeatUpdate := mongomanager.Seat{
-		SubscriptionID: sourceSeat.SubscriptionID,
-		UID:            sourceSeat.UID,
-		EMail:          sourceSeat.EMail,
-		EMailVerified:  sourceSeat.EMailVerified,
-		Roles:          filteredRoles,
-	}
-	return paymentHandler.MongoConnection.UpdateSeat(c, sourceSeatUpdate)
+	return paymentHandler.MongoConnection.RemoveSeatRole(c, sourceSeat.SubscriptionID, sourceSeat.UID, mongomanager.RoleOwner)
 }
 
-func (paymentHandler *PaymentHandler) sendConfirmationMail() {
-	paymentHandler.Log.Warn("Send confirmation mail to the new owner")
-	paymentHandler.Log.Warn("Send confirmation mail to the old owner")
+func (paymentHandler *PaymentHandler) sendConfirmationMail(c context.Context, sourceSeat, destinationSeat mongomanager.Seat) {
+	mails := []emailmanager.EMail{
+		{
+			To:      []string{destinationSeat.EMail},
+			Subject: "You are now the owner of your scalecloud subscription",
+			Body: `
+        <html>
+        <body>
+            <p>Hello,</p>
+            <p>` + html.EscapeString(sourceSeat.EMail) + ` transferred the ownership of the subscription ` + html.EscapeString(sourceSeat.SubscriptionID) + ` to you.</p>
+            <p>From now on you are responsible for billing and payment of this subscription.</p>
+        </body>
+        </html>
+    `,
+		},
+		{
+			To:      []string{sourceSeat.EMail},
+			Subject: "Ownership of your scalecloud subscription was transferred",
+			Body: `
+        <html>
+        <body>
+            <p>Hello,</p>
+            <p>You transferred the ownership of the subscription ` + html.EscapeString(sourceSeat.SubscriptionID) + ` to ` + html.EscapeString(destinationSeat.EMail) + `.</p>
+            <p>If you did not initiate this transfer, please contact our support immediately.</p>
+        </body>
+        </html>
+    `,
+		},
+	}
+	for _, mail := range mails {
+		err := paymentHandler.EMailConnection.SendEMail(mail)
+		if err != nil {
+			paymentHandler.log(c).Error("Error sending owner transfer confirmation", zap.Strings("to", mail.To), zap.Error(err))
+		}
+	}
 }
@@ -0,0 +1,252 @@
+package stripemanager
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/emailmanager"
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+const (
+	ownerTransferExpiry = 72 * time.Hour
+	ownerTransferLink   = "https://www.scalecloud.de/dashboard/owner-transfer/"
+)
+
+func (paymentHandler *PaymentHandler) RequestOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, request OwnerTransferRequest) (OwnerTransferReply, error) {
+	ownerSeat, err := paymentHandler.MongoConnection.GetOwnerSeat(c, request.SubscriptionID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	if ownerSeat.UID == request.UID {
+		return OwnerTransferReply{}, errors.New("seat is already the owner")
+	}
+	return paymentHandler.requestOwnerTransfer(c, tokenDetails, ownerSeat, request.UID)
+}
+
+func (paymentHandler *PaymentHandler) requestOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, ownerSeat mongomanager.Seat, destinationUID string) (OwnerTransferReply, error) {
+	err := hasOwnerTriggeredOwnerTransfer(tokenDetails, ownerSeat)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	destinationSeat, err := paymentHandler.MongoConnection.GetSeat(c, ownerSeat.SubscriptionID, destinationUID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.validateOwnerTransfer(c, ownerSeat, destinationSeat)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	token, err := generateOwnerTransferToken()
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	now := time.Now()
+	ownerTransfer := mongomanager.OwnerTransfer{
+		SubscriptionID:   ownerSeat.SubscriptionID,
+		SourceUID:        ownerSeat.UID,
+		SourceEMail:      ownerSeat.EMail,
+		DestinationUID:   destinationSeat.UID,
+		DestinationEMail: destinationSeat.EMail,
+		TokenHash:        hashOwnerTransferToken(token),
+		Status:           mongomanager.OwnerTransferStatusPending,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(ownerTransferExpiry),
+		UpdatedAt:        now,
+	}
+	err = paymentHandler.MongoConnection.CreateOwnerTransfer(c, ownerTransfer)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.sendOwnerTransferRequestMail(ownerTransfer, token)
+	if err != nil {
+		paymentHandler.log(c).Error("Error sending owner transfer request", zap.String("subscriptionID", ownerTransfer.SubscriptionID), zap.Error(err))
+		rollbackErr := paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusPending, mongomanager.OwnerTransferStatusCanceled)
+		if rollbackErr != nil {
+			paymentHandler.log(c).Error("Error canceling owner transfer", zap.Error(rollbackErr))
+		}
+		return OwnerTransferReply{}, errors.New("error sending owner transfer request")
+	}
+	paymentHandler.log(c).Info("Owner transfer requested", zap.String("subscriptionID", ownerTransfer.SubscriptionID), zap.String("destinationUID", ownerTransfer.DestinationUID))
+	reply := toOwnerTransferReply(ownerTransfer)
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionOwnerTransferRequest, ownerTransfer.SubscriptionID, ownerTransfer.DestinationUID, nil, reply)
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) GetPendingOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, request PendingOwnerTransferRequest) (OwnerTransferReply, error) {
+	ownerTransfer, err := paymentHandler.MongoConnection.GetPendingOwnerTransfer(c, request.SubscriptionID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	if time.Now().After(ownerTransfer.ExpiresAt) {
+		ownerTransfer.Status = mongomanager.OwnerTransferStatusExpired
+	}
+	return toOwnerTransferReply(ownerTransfer), nil
+}
+
+func (paymentHandler *PaymentHandler) CancelOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, request PendingOwnerTransferRequest) (OwnerTransferReply, error) {
+	ownerTransfer, err := paymentHandler.MongoConnection.GetPendingOwnerTransfer(c, request.SubscriptionID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusPending, mongomanager.OwnerTransferStatusCanceled)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	before := toOwnerTransferReply(ownerTransfer)
+	ownerTransfer.Status = mongomanager.OwnerTransferStatusCanceled
+	reply := toOwnerTransferReply(ownerTransfer)
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionOwnerTransferCancel, ownerTransfer.SubscriptionID, ownerTransfer.DestinationUID, before, reply)
+	paymentHandler.log(c).Info("Owner transfer canceled", zap.String("subscriptionID", ownerTransfer.SubscriptionID))
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) DeclineOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, request OwnerTransferTokenRequest) (OwnerTransferReply, error) {
+	ownerTransfer, err := paymentHandler.getPendingOwnerTransferForDestination(c, tokenDetails, request.Token)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusPending, mongomanager.OwnerTransferStatusDeclined)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	before := toOwnerTransferReply(ownerTransfer)
+	ownerTransfer.Status = mongomanager.OwnerTransferStatusDeclined
+	reply := toOwnerTransferReply(ownerTransfer)
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionOwnerTransferDecline, ownerTransfer.SubscriptionID, ownerTransfer.DestinationUID, before, reply)
+	paymentHandler.log(c).Info("Owner transfer declined", zap.String("subscriptionID", ownerTransfer.SubscriptionID))
+	paymentHandler.sendOwnerTransferDeclinedMail(c, ownerTransfer)
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) AcceptOwnerTransfer(c context.Context, tokenDetails firebasemanager.TokenDetails, request OwnerTransferTokenRequest) (OwnerTransferReply, error) {
+	ownerTransfer, err := paymentHandler.getPendingOwnerTransferForDestination(c, tokenDetails, request.Token)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	ownerSeat, err := paymentHandler.MongoConnection.GetOwnerSeat(c, ownerTransfer.SubscriptionID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	if ownerSeat.UID != ownerTransfer.SourceUID {
+		return OwnerTransferReply{}, errors.New("owner of the subscription has changed since the transfer was requested")
+	}
+	destinationSeat, err := paymentHandler.MongoConnection.GetSeat(c, ownerTransfer.SubscriptionID, ownerTransfer.DestinationUID)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.validateOwnerTransfer(c, ownerSeat, destinationSeat)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusPending, mongomanager.OwnerTransferStatusAccepted)
+	if err != nil {
+		return OwnerTransferReply{}, err
+	}
+	err = paymentHandler.handleStripeOwnerTransfer(c, destinationSeat, ownerSeat)
+	if err != nil {
+		rollbackErr := paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusAccepted, mongomanager.OwnerTransferStatusPending)
+		if rollbackErr != nil {
+			paymentHandler.log(c).Error("Error resetting owner transfer", zap.Error(rollbackErr))
+		}
+		return OwnerTransferReply{}, err
+	}
+	before := toOwnerTransferReply(ownerTransfer)
+	ownerTransfer.Status = mongomanager.OwnerTransferStatusAccepted
+	reply := toOwnerTransferReply(ownerTransfer)
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionOwnerTransferAccept, ownerTransfer.SubscriptionID, ownerTransfer.DestinationUID, before, reply)
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) getPendingOwnerTransferForDestination(c context.Context, tokenDetails firebasemanager.TokenDetails, token string) (mongomanager.OwnerTransfer, error) {
+	ownerTransfer, err := paymentHandler.MongoConnection.GetOwnerTransferByTokenHash(c, hashOwnerTransferToken(token))
+	if err != nil {
+		return mongomanager.OwnerTransfer{}, err
+	}
+	if ownerTransfer.DestinationUID != tokenDetails.UID {
+		paymentHandler.log(c).Warn("user with UID " + tokenDetails.UID + " tried to answer owner transfer of subscriptionID " + ownerTransfer.SubscriptionID)
+		return mongomanager.OwnerTransfer{}, errors.New(http.StatusText(http.StatusForbidden))
+	}
+	if ownerTransfer.Status != mongomanager.OwnerTransferStatusPending {
+		return mongomanager.OwnerTransfer{}, errors.New("owner transfer is " + string(ownerTransfer.Status))
+	}
+	if time.Now().After(ownerTransfer.ExpiresAt) {
+		err = paymentHandler.MongoConnection.UpdateOwnerTransferStatus(c, ownerTransfer.TokenHash, mongomanager.OwnerTransferStatusPending, mongomanager.OwnerTransferStatusExpired)
+		if err != nil {
+			paymentHandler.log(c).Error("Error expiring owner transfer", zap.Error(err))
+		}
+		return mongomanager.OwnerTransfer{}, errors.New("owner transfer is expired")
+	}
+	return ownerTransfer, nil
+}
+
+func generateOwnerTransferToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return "", errors.New("failed to generate owner transfer token")
+	}
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
+func hashOwnerTransferToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func toOwnerTransferReply(ownerTransfer mongomanager.OwnerTransfer) OwnerTransferReply {
+	return OwnerTransferReply{
+		SubscriptionID:   ownerTransfer.SubscriptionID,
+		SourceEMail:      ownerTransfer.SourceEMail,
+		DestinationEMail: ownerTransfer.DestinationEMail,
+		Status:           ownerTransfer.Status,
+		ExpiresAt:        ownerTransfer.ExpiresAt,
+	}
+}
+
+func (paymentHandler *PaymentHandler) sendOwnerTransferRequestMail(ownerTransfer mongomanager.OwnerTransfer, token string) error {
+	link := ownerTransferLink + token
+	return paymentHandler.EMailConnection.SendEMail(emailmanager.EMail{
+		To:      []string{ownerTransfer.DestinationEMail},
+		Subject: "You were asked to become the owner of a scalecloud subscription",
+		Body: `
+        <html>
+        <body>
+            <p>Hello,</p>
+            <p>` + html.EscapeString(ownerTransfer.SourceEMail) + ` would like to transfer the ownership of the subscription ` + html.EscapeString(ownerTransfer.SubscriptionID) + ` to you.</p>
+            <p>As the owner you will be responsible for billing and payment of this subscription.</p>
+            <p><a href="` + link + `">Review the transfer</a></p>
+            <p>The link expires on ` + ownerTransfer.ExpiresAt.UTC().Format(time.RFC1123) + `. If you do not want to become the owner, you can decline or ignore this E-Mail.</p>
+        </body>
+        </html>
+    `,
+	})
+}
+
+func (paymentHandler *PaymentHandler) sendOwnerTransferDeclinedMail(c context.Context, ownerTransfer mongomanager.OwnerTransfer) {
+	err := paymentHandler.EMailConnection.SendEMail(emailmanager.EMail{
+		To:      []string{ownerTransfer.SourceEMail},
+		Subject: "Owner transfer of your scalecloud subscription was declined",
+		Body: `
+        <html>
+        <body>
+            <p>Hello,</p>
+            <p>` + html.EscapeString(ownerTransfer.DestinationEMail) + ` declined to become the owner of the subscription ` + html.EscapeString(ownerTransfer.SubscriptionID) + `.</p>
+            <p>You remain the owner of this subscription.</p>
+        </body>
+        </html>
+    `,
+	})
+	if err != nil {
+		paymentHandler.log(c).Error("Error sending owner transfer declined mail", zap.String("subscriptionID", ownerTransfer.SubscriptionID), zap.Error(err))
+	}
+}
@@ -0,0 +1,28 @@
+package stripemanager
+
+import (
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+)
+
+type OwnerTransferRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+	UID            string `json:"uid" validate:"required"`
+}
+
+type PendingOwnerTransferRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+}
+
+type OwnerTransferTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type OwnerTransferReply struct {
+	SubscriptionID   string                           `json:"subscriptionID" validate:"required"`
+	SourceEMail      string                           `json:"sourceEMail" validate:"required"`
+	DestinationEMail string                           `json:"destinationEMail" validate:"required"`
+	Status           mongomanager.OwnerTransferStatus `json:"status" validate:"required"`
+	ExpiresAt        time.Time                        `json:"expiresAt" validate:"required"`
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/product"
+	"go.uber.org/zap"
 )
 
 func (paymentHandler *PaymentHandler) GetProductTiers(c context.Context, prodType ProductType) (ProductTiersReply, error) {
@@ -58,14 +59,27 @@ func (paymentHandler *PaymentHandler) GetProductTiers(c context.Context, prodTyp
 		if err != nil {
 			return ProductTiersReply{}, errors.New("price not found")
 		}
+		taxCalculated := true
+		totals, err := paymentHandler.StripeConnection.calculatePriceTotals(c, price, price.UnitAmount, product.ID, "")
+		if err != nil {
+			paymentHandler.log(c).Warn("Falling back to the net price", zap.String("productID", product.ID), zap.Error(err))
+			taxCalculated = false
+			totals = priceTotals{
+				exclusiveTax: price.UnitAmount,
+				inclusiveTax: price.UnitAmount,
+			}
+		}
 		productTier := ProductTier{
-			ProductType:   prodType,
-			ProductID:     product.ID,
-			Name:          product.Name,
-			StorageAmount: iStorageAmount,
-			StorageUnit:   storageUnit,
-			TrialDays:     iTrialPeriodDays,
-			PricePerMonth: price.UnitAmount,
+			ProductType:               prodType,
+			ProductID:                 product.ID,
+			Name:                      product.Name,
+			StorageAmount:             iStorageAmount,
+			StorageUnit:               storageUnit,
+			TrialDays:                 iTrialPeriodDays,
+			PricePerMonth:             price.UnitAmount,
+			PricePerMonthExclusiveTax: totals.exclusiveTax,
+			PricePerMonthInclusiveTax: totals.inclusiveTax,
+			TaxCalculated:             taxCalculated,
 		}
 		productTiers = append(productTiers, productTier)
 	}
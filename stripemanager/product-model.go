@@ -17,11 +17,14 @@ type ProductTiersReply struct {
 }
 
 type ProductTier struct {
-	ProductType   ProductType `json:"productType" validate:"required"`
-	ProductID     string      `json:"productID" validate:"required"`
-	Name          string      `json:"name" validate:"required"`
-	StorageAmount int         `json:"storageAmount" validate:"required"`
-	StorageUnit   string      `json:"storageUnit" validate:"required"`
-	TrialDays     int64       `json:"trialDays" validate:"required"`
-	PricePerMonth int64       `json:"pricePerMonth" validate:"required"`
+	ProductType               ProductType `json:"productType" validate:"required"`
+	ProductID                 string      `json:"productID" validate:"required"`
+	Name                      string      `json:"name" validate:"required"`
+	StorageAmount             int         `json:"storageAmount" validate:"required"`
+	StorageUnit               string      `json:"storageUnit" validate:"required"`
+	TrialDays                 int64       `json:"trialDays" validate:"required"`
+	PricePerMonth             int64       `json:"pricePerMonth" validate:"required"`
+	PricePerMonthExclusiveTax int64       `json:"pricePerMonthExclusiveTax" validate:"required"`
+	PricePerMonthInclusiveTax int64       `json:"pricePerMonthInclusiveTax" validate:"required"`
+	TaxCalculated             bool        `json:"taxCalculated"`
 }
@@ -0,0 +1,171 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/invoice"
+	"github.com/stripe/stripe-go/v82/promotioncode"
+	"github.com/stripe/stripe-go/v82/subscription"
+	"go.uber.org/zap"
+)
+
+var ErrPromotionCodeInvalid = errors.New("promotion code is invalid or expired")
+
+func (stripeConnection *StripeConnection) getPromotionCode(ctx context.Context, code string, product *stripe.Product, price *stripe.Price, quantity int64, customerID string) (*stripe.PromotionCode, error) {
+	stripe.Key = stripeConnection.Key
+	params := &stripe.PromotionCodeListParams{
+		Code:   stripe.String(strings.TrimSpace(code)),
+		Active: stripe.Bool(true),
+	}
+	params.Context = ctx
+	params.AddExpand("data.coupon.applies_to")
+	var promo *stripe.PromotionCode
+	iter := promotioncode.List(params)
+	if iter.Next() {
+		promo = iter.PromotionCode()
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error searching promotion code", zap.Error(err))
+		return nil, err
+	}
+	if promo == nil || promo.Coupon == nil {
+		return nil, ErrPromotionCodeInvalid
+	}
+	err := stripeConnection.checkPromotionCode(ctx, promo, product, price, quantity, customerID)
+	if err != nil {
+		stripeConnection.Log.Info("Promotion code rejected", zap.String("promotionCodeID", promo.ID), zap.String("customerID", customerID), zap.Error(err))
+		return nil, err
+	}
+	return promo, nil
+}
+
+func (stripeConnection *StripeConnection) checkPromotionCode(ctx context.Context, promo *stripe.PromotionCode, product *stripe.Product, price *stripe.Price, quantity int64, customerID string) error {
+	now := time.Now().Unix()
+	coupon := promo.Coupon
+	if !promo.Active || !coupon.Valid {
+		return ErrPromotionCodeInvalid
+	}
+	if (promo.ExpiresAt != 0 && promo.ExpiresAt <= now) || (coupon.RedeemBy != 0 && coupon.RedeemBy <= now) {
+		return ErrPromotionCodeInvalid
+	}
+	if (promo.MaxRedemptions != 0 && promo.TimesRedeemed >= promo.MaxRedemptions) || (coupon.MaxRedemptions != 0 && coupon.TimesRedeemed >= coupon.MaxRedemptions) {
+		return errors.New("promotion code has reached its redemption limit")
+	}
+	if promo.Customer != nil && promo.Customer.ID != customerID {
+		return ErrPromotionCodeInvalid
+	}
+	if coupon.AppliesTo != nil && len(coupon.AppliesTo.Products) > 0 && !slices.Contains(coupon.AppliesTo.Products, product.ID) {
+		return errors.New("promotion code does not apply to this product")
+	}
+	if coupon.AmountOff != 0 && coupon.Currency != price.Currency {
+		return errors.New("promotion code does not apply to this currency")
+	}
+	if promo.Restrictions == nil {
+		return nil
+	}
+	if promo.Restrictions.MinimumAmount != 0 && promo.Restrictions.MinimumAmountCurrency == price.Currency && price.UnitAmount*quantity < promo.Restrictions.MinimumAmount {
+		return errors.New("order amount is below the minimum for this promotion code")
+	}
+	if promo.Restrictions.FirstTimeTransaction {
+		hasPaid, err := stripeConnection.hasPaidInvoice(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		if hasPaid {
+			return errors.New("promotion code is only valid for first-time customers")
+		}
+	}
+	return nil
+}
+
+func (stripeConnection *StripeConnection) hasPaidInvoice(ctx context.Context, customerID string) (bool, error) {
+	params := &stripe.InvoiceListParams{
+		Customer: stripe.String(customerID),
+		Status:   stripe.String(string(stripe.InvoiceStatusPaid)),
+	}
+	params.Context = ctx
+	iter := invoice.List(params)
+	for iter.Next() {
+		if iter.Invoice().AmountPaid > 0 {
+			return true, nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		stripeConnection.Log.Error("Error listing paid invoices", zap.String("customerID", customerID), zap.Error(err))
+		return false, err
+	}
+	return false, nil
+}
+
+func discountedAmount(amount int64, coupon *stripe.Coupon) int64 {
+	if coupon.PercentOff > 0 {
+		amount -= int64(math.Round(float64(amount) * coupon.PercentOff / 100))
+	}
+	if coupon.AmountOff > 0 {
+		amount -= coupon.AmountOff
+	}
+	return max(amount, 0)
+}
+
+func (stripeConnection *StripeConnection) previewCheckoutDiscount(ctx context.Context, promo *stripe.PromotionCode, price *stripe.Price, productID, taxCustomerID string) (*CheckoutDiscount, error) {
+	coupon := promo.Coupon
+	discount := &CheckoutDiscount{
+		Code:             promo.Code,
+		Name:             coupon.Name,
+		PercentOff:       coupon.PercentOff,
+		AmountOff:        coupon.AmountOff,
+		Duration:         string(coupon.Duration),
+		DurationInMonths: coupon.DurationInMonths,
+		PricePerMonth:    discountedAmount(price.UnitAmount, coupon),
+	}
+	if discount.PricePerMonth == 0 {
+		return discount, nil
+	}
+	totals, err := stripeConnection.calculatePriceTotals(ctx, price, discount.PricePerMonth, productID, taxCustomerID)
+	if err != nil {
+		return nil, err
+	}
+	discount.PricePerMonthExclusiveTax = totals.exclusiveTax
+	discount.PricePerMonthInclusiveTax = totals.inclusiveTax
+	return discount, nil
+}
+
+func (stripeConnection *StripeConnection) getSubscriptionWithDiscounts(ctx context.Context, subscriptionID string) (*stripe.Subscription, error) {
+	stripe.Key = stripeConnection.Key
+	params := &stripe.SubscriptionParams{}
+	params.Context = ctx
+	params.AddExpand("discounts")
+	params.AddExpand("discounts.promotion_code")
+	return subscription.Get(subscriptionID, params)
+}
+
+func activeDiscounts(sub *stripe.Subscription) []SubscriptionDiscount {
+	now := time.Now().Unix()
+	discounts := []SubscriptionDiscount{}
+	for _, discount := range sub.Discounts {
+		if discount == nil || discount.Coupon == nil || (discount.End != 0 && discount.End <= now) {
+			continue
+		}
+		subscriptionDiscount := SubscriptionDiscount{
+			Name:             discountName(discount),
+			PercentOff:       discount.Coupon.PercentOff,
+			AmountOff:        discount.Coupon.AmountOff,
+			Currency:         string(discount.Coupon.Currency),
+			Duration:         string(discount.Coupon.Duration),
+			DurationInMonths: discount.Coupon.DurationInMonths,
+			Start:            discount.Start,
+			End:              discount.End,
+		}
+		if discount.PromotionCode != nil {
+			subscriptionDiscount.PromotionCode = discount.PromotionCode.Code
+		}
+		discounts = append(discounts, subscriptionDiscount)
+	}
+	return discounts
+}
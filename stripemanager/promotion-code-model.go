@@ -0,0 +1,25 @@
+package stripemanager
+
+type CheckoutDiscount struct {
+	Code                      string  `json:"code" validate:"required"`
+	Name                      string  `json:"name"`
+	PercentOff                float64 `json:"percentOff" validate:"gte=0"`
+	AmountOff                 int64   `json:"amountOff" validate:"gte=0"`
+	Duration                  string  `json:"duration" validate:"required"`
+	DurationInMonths          int64   `json:"durationInMonths" validate:"gte=0"`
+	PricePerMonth             int64   `json:"pricePerMonth" validate:"gte=0"`
+	PricePerMonthExclusiveTax int64   `json:"pricePerMonthExclusiveTax" validate:"gte=0"`
+	PricePerMonthInclusiveTax int64   `json:"pricePerMonthInclusiveTax" validate:"gte=0"`
+}
+
+type SubscriptionDiscount struct {
+	Name             string  `json:"name"`
+	PromotionCode    string  `json:"promotion_code"`
+	PercentOff       float64 `json:"percent_off"`
+	AmountOff        int64   `json:"amount_off"`
+	Currency         string  `json:"currency"`
+	Duration         string  `json:"duration"`
+	DurationInMonths int64   `json:"duration_in_months"`
+	Start            int64   `json:"start"`
+	End              int64   `json:"end"`
+}
@@ -0,0 +1,107 @@
+package stripemanager
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) ExportSeats(c context.Context, tokenDetails firebasemanager.TokenDetails, request ExportSeatsRequest) (func(io.Writer) error, error) {
+	err := paymentHandler.syncUnverifiedSeatsOfSubscription(c, request.SubscriptionID)
+	if err != nil {
+		paymentHandler.log(c).Warn("Exporting seats without syncing email verification", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+	}
+	switch request.Format {
+	case ExportFormatCSV:
+		return func(writer io.Writer) error {
+			return paymentHandler.exportSeatsCSV(c, request.SubscriptionID, writer)
+		}, nil
+	case ExportFormatJSON:
+		return func(writer io.Writer) error {
+			return paymentHandler.exportSeatsJSON(c, request.SubscriptionID, writer)
+		}, nil
+	default:
+		return nil, errors.New("unsupported export format " + string(request.Format))
+	}
+}
+
+func (paymentHandler *PaymentHandler) exportSeatsCSV(c context.Context, subscriptionID string, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write([]string{"email", "roles", "emailVerified", "invitedAt", "joinedAt"})
+	if err != nil {
+		return err
+	}
+	err = paymentHandler.MongoConnection.StreamSeats(c, subscriptionID, func(seat mongomanager.Seat) error {
+		exported := toExportedSeat(seat)
+		roles := make([]string, 0, len(exported.Roles))
+		for _, role := range exported.Roles {
+			roles = append(roles, string(role))
+		}
+		return csvWriter.Write([]string{
+			exported.EMail,
+			strings.Join(roles, ";"),
+			strconv.FormatBool(exported.EMailVerified),
+			formatExportTime(exported.InvitedAt),
+			formatExportTime(exported.JoinedAt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func (paymentHandler *PaymentHandler) exportSeatsJSON(c context.Context, subscriptionID string, writer io.Writer) error {
+	_, err := io.WriteString(writer, "[")
+	if err != nil {
+		return err
+	}
+	first := true
+	err = paymentHandler.MongoConnection.StreamSeats(c, subscriptionID, func(seat mongomanager.Seat) error {
+		data, err := json.Marshal(toExportedSeat(seat))
+		if err != nil {
+			return err
+		}
+		if !first {
+			_, err = io.WriteString(writer, ",")
+			if err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = writer.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "]")
+	return err
+}
+
+func toExportedSeat(seat mongomanager.Seat) ExportedSeat {
+	return ExportedSeat{
+		EMail:         seat.EMail,
+		Roles:         seat.Roles,
+		EMailVerified: seat.EMailVerified != nil && *seat.EMailVerified,
+		InvitedAt:     seat.InvitedAt,
+		JoinedAt:      seat.JoinedAt,
+	}
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,27 @@
+package stripemanager
+
+import (
+	"time"
+
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+type ExportSeatsRequest struct {
+	SubscriptionID string       `json:"subscriptionID" validate:"required"`
+	Format         ExportFormat `json:"format" validate:"required,oneof=csv json"`
+}
+
+type ExportedSeat struct {
+	EMail         string              `json:"email" validate:"required"`
+	Roles         []mongomanager.Role `json:"roles" validate:"required"`
+	EMailVerified bool                `json:"emailVerified"`
+	InvitedAt     *time.Time          `json:"invitedAt"`
+	JoinedAt      *time.Time          `json:"joinedAt"`
+}
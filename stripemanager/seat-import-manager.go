@@ -0,0 +1,193 @@
+package stripemanager
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+const (
+	maxImportSeatRows      = 500
+	importSeatsConcurrency = 5
+)
+
+var importableRoles = []mongomanager.Role{
+	mongomanager.RoleAdministrator,
+	mongomanager.RoleUser,
+	mongomanager.RoleBilling,
+}
+
+func ParseImportSeatsCSV(reader io.Reader) ([]ImportSeatRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.New("invalid CSV: " + err.Error())
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV is empty")
+	}
+	emailColumn, rolesColumn := -1, -1
+	for i, column := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "email", "e-mail":
+			emailColumn = i
+		case "roles", "role":
+			rolesColumn = i
+		}
+	}
+	if emailColumn == -1 || rolesColumn == -1 {
+		return nil, errors.New("CSV header must contain the columns email and roles")
+	}
+	rows := make([]ImportSeatRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := ImportSeatRow{}
+		if emailColumn < len(record) {
+			row.EMail = strings.TrimSpace(record[emailColumn])
+		}
+		if rolesColumn < len(record) {
+			for _, role := range strings.FieldsFunc(record[rolesColumn], isRoleSeparator) {
+				row.Roles = append(row.Roles, mongomanager.Role(strings.TrimSpace(role)))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isRoleSeparator(r rune) bool {
+	return r == ';' || r == '|'
+}
+
+func (paymentHandler *PaymentHandler) ImportSeats(c context.Context, tokenDetails firebasemanager.TokenDetails, request ImportSeatsRequest) (ImportSeatsReply, error) {
+	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, mongomanager.PermissionSeatsWrite)
+	if err != nil {
+		return ImportSeatsReply{}, err
+	}
+	if len(request.Seats) == 0 {
+		return ImportSeatsReply{}, errors.New("no seats to import")
+	}
+	if len(request.Seats) > maxImportSeatRows {
+		return ImportSeatsReply{}, errors.New("too many seats, at most " + strconv.Itoa(maxImportSeatRows) + " rows can be imported at once")
+	}
+	actorSeat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, tokenDetails.UID)
+	if err != nil {
+		return ImportSeatsReply{}, err
+	}
+	seats, err := paymentHandler.MongoConnection.GetAllSeats(c, request.SubscriptionID)
+	if err != nil {
+		return ImportSeatsReply{}, err
+	}
+	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
+	if err != nil {
+		return ImportSeatsReply{}, errors.New("subscription not found")
+	}
+	quantity := subscription.Items.Data[0].Quantity
+	if quantity == 0 {
+		return ImportSeatsReply{}, errors.New("quantity is 0")
+	}
+
+	results := make([]ImportSeatResult, len(request.Seats))
+	var validRows []int
+	seen := make(map[string]bool)
+	for i, row := range request.Seats {
+		results[i] = ImportSeatResult{
+			Row:   i + 1,
+			EMail: row.EMail,
+			Roles: row.Roles,
+		}
+		err := validateImportSeatRow(row, actorSeat, seats, seen)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		seen[strings.ToLower(row.EMail)] = true
+		validRows = append(validRows, i)
+	}
+	available := quantity - int64(len(seats))
+	if int64(len(validRows)) > available {
+		return ImportSeatsReply{}, errors.New("not enough seats available: " + strconv.Itoa(len(validRows)) + " requested, " + strconv.FormatInt(max(available, 0), 10) + " available")
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, importSeatsConcurrency)
+	for _, i := range validRows {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			row := request.Seats[i]
+			seat, err := paymentHandler.inviteSeat(c, request.SubscriptionID, row.EMail, row.Roles)
+			if err != nil {
+				paymentHandler.log(c).Warn("Error importing seat", zap.String("subscriptionID", request.SubscriptionID), zap.String("email", row.EMail), zap.Error(err))
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Success = true
+			results[i].UID = seat.UID
+			paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatImport, request.SubscriptionID, seat.UID, nil, seat)
+		}(i)
+	}
+	wg.Wait()
+
+	reply := ImportSeatsReply{
+		SubscriptionID: request.SubscriptionID,
+		MaxSeats:       quantity,
+		Results:        results,
+	}
+	for _, result := range results {
+		if result.Success {
+			reply.Imported++
+		} else {
+			reply.Failed++
+		}
+	}
+	paymentHandler.log(c).Info("Imported seats", zap.String("subscriptionID", request.SubscriptionID), zap.Int("imported", reply.Imported), zap.Int("failed", reply.Failed))
+	return reply, nil
+}
+
+func validateImportSeatRow(row ImportSeatRow, actorSeat mongomanager.Seat, seats []mongomanager.Seat, seen map[string]bool) error {
+	if !IsValidEmail(row.EMail) {
+		return errors.New("E-Mail is invalid")
+	}
+	if len(row.Roles) == 0 {
+		return errors.New("no role selected")
+	}
+	for _, role := range row.Roles {
+		if role == mongomanager.RoleOwner {
+			return errors.New("cannot add user as owner")
+		}
+		if !isImportableRole(role) {
+			return errors.New("unknown role " + string(role))
+		}
+		if !mongomanager.CanGrantRole(actorSeat, role) {
+			return errors.New("not allowed to grant role " + string(role))
+		}
+	}
+	if seen[strings.ToLower(row.EMail)] {
+		return errors.New("duplicate E-Mail in import")
+	}
+	if containsEmail(seats, row.EMail) {
+		return errors.New("seat already exists")
+	}
+	return nil
+}
+
+func isImportableRole(role mongomanager.Role) bool {
+	for _, importableRole := range importableRoles {
+		if role == importableRole {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package stripemanager
+
+import "github.com/scalecloud/scalecloud.de-api/mongomanager"
+
+type ImportSeatRow struct {
+	EMail string              `json:"email" validate:"required"`
+	Roles []mongomanager.Role `json:"roles" validate:"required"`
+}
+
+type ImportSeatsRequest struct {
+	SubscriptionID string          `json:"subscriptionID" validate:"required"`
+	Seats          []ImportSeatRow `json:"seats" validate:"required"`
+}
+
+type ImportSeatResult struct {
+	Row     int                 `json:"row" validate:"gte=1"`
+	EMail   string              `json:"email"`
+	Roles   []mongomanager.Role `json:"roles"`
+	Success bool                `json:"success"`
+	UID     string              `json:"uid,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+type ImportSeatsReply struct {
+	SubscriptionID string             `json:"subscriptionID" validate:"required"`
+	MaxSeats       int64              `json:"maxSeats" validate:"required"`
+	Imported       int                `json:"imported" validate:"gte=0"`
+	Failed         int                `json:"failed" validate:"gte=0"`
+	Results        []ImportSeatResult `json:"results" validate:"required"`
+}
@@ -0,0 +1,69 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/http"
+
+	"github.com/scalecloud/scalecloud.de-api/emailmanager"
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) LeaveSubscription(c context.Context, tokenDetails firebasemanager.TokenDetails, request LeaveSubscriptionRequest) (LeaveSubscriptionReply, error) {
+	seat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, tokenDetails.UID)
+	if err != nil || seat.UID == "" {
+		paymentHandler.log(c).Warn("user with UID " + tokenDetails.UID + " tried to leave subscriptionID " + request.SubscriptionID + " but has no seat")
+		return LeaveSubscriptionReply{}, errors.New(http.StatusText(http.StatusForbidden))
+	}
+	if hasRole(seat.Roles, mongomanager.RoleOwner) {
+		return LeaveSubscriptionReply{}, errors.New("owner cannot leave the subscription, transfer the ownership first")
+	}
+	err = paymentHandler.MongoConnection.DeleteSeat(c, seat)
+	if err != nil {
+		return LeaveSubscriptionReply{}, err
+	}
+	paymentHandler.log(c).Info("Seat left subscription", zap.String("subscriptionID", seat.SubscriptionID), zap.String("uid", seat.UID))
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatLeave, seat.SubscriptionID, seat.UID, seat, nil)
+	paymentHandler.sendSeatLeftMail(c, seat)
+	reply := LeaveSubscriptionReply{
+		SubscriptionID: seat.SubscriptionID,
+		Success:        true,
+	}
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) sendSeatLeftMail(c context.Context, leftSeat mongomanager.Seat) {
+	seats, err := paymentHandler.MongoConnection.GetAllSeats(c, leftSeat.SubscriptionID)
+	if err != nil {
+		paymentHandler.log(c).Error("Error loading administrators", zap.String("subscriptionID", leftSeat.SubscriptionID), zap.Error(err))
+		return
+	}
+	var administrators []string
+	for _, seat := range seats {
+		if mongomanager.SeatHasPermission(seat, mongomanager.PermissionSeatsWrite) {
+			administrators = append(administrators, seat.EMail)
+		}
+	}
+	if len(administrators) == 0 {
+		return
+	}
+	err = paymentHandler.EMailConnection.SendEMail(emailmanager.EMail{
+		To:      administrators,
+		Subject: "A user left your scalecloud subscription",
+		Body: `
+        <html>
+        <body>
+            <p>Hello,</p>
+            <p>` + html.EscapeString(leftSeat.EMail) + ` has left the subscription ` + html.EscapeString(leftSeat.SubscriptionID) + `.</p>
+            <p>The seat is available again and can be assigned to another user.</p>
+        </body>
+        </html>
+    `,
+	})
+	if err != nil {
+		paymentHandler.log(c).Error("Error sending seat left mail", zap.String("subscriptionID", leftSeat.SubscriptionID), zap.Error(err))
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"net/http"
 	"net/mail"
+	"strings"
+	"time"
 
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
 )
 
 func (paymentHandler *PaymentHandler) GetMyPermission(c context.Context, tokenDetails firebasemanager.TokenDetails, request PermissionRequest) (PermissionReply, error) {
@@ -16,7 +19,7 @@ func (paymentHandler *PaymentHandler) GetMyPermission(c context.Context, tokenDe
 		return PermissionReply{}, err
 	}
 	if mySeat.UID == "" {
-		paymentHandler.Log.Warn("user with UID " + tokenDetails.UID + " tried to access subscriptionID " + request.SubscriptionID + " but has no seat")
+		paymentHandler.log(c).Warn("user with UID " + tokenDetails.UID + " tried to access subscriptionID " + request.SubscriptionID + " but has no seat")
 		return PermissionReply{}, errors.New(http.StatusText(http.StatusForbidden))
 	}
 	reply := PermissionReply{
@@ -25,22 +28,47 @@ func (paymentHandler *PaymentHandler) GetMyPermission(c context.Context, tokenDe
 	return reply, nil
 }
 
-func (paymentHandler *PaymentHandler) GetSubscriptionListSeats(c context.Context, tokenDetails firebasemanager.TokenDetails, request ListSeatRequest) (ListSeatReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
+func (paymentHandler *PaymentHandler) GetMyPermissions(c context.Context, tokenDetails firebasemanager.TokenDetails, request PermissionRequest) (EffectivePermissionsReply, error) {
+	reply, err := paymentHandler.GetMyPermission(c, tokenDetails, request)
 	if err != nil {
-		return ListSeatReply{}, err
+		return EffectivePermissionsReply{}, err
 	}
-	totalResults, err := paymentHandler.MongoConnection.CountSeats(c, request.SubscriptionID)
+	return EffectivePermissionsReply{
+		SubscriptionID: request.SubscriptionID,
+		Roles:          reply.MySeat.Roles,
+		Permissions:    mongomanager.SeatPermissions(reply.MySeat),
+	}, nil
+}
+
+func (paymentHandler *PaymentHandler) GetSubscriptionListSeats(c context.Context, tokenDetails firebasemanager.TokenDetails, request ListSeatRequest) (ListSeatReply, error) {
+	query := mongomanager.SeatQuery{
+		SubscriptionID: request.SubscriptionID,
+		Search:         request.Search,
+		Role:           request.Role,
+		EMailVerified:  request.EMailVerified,
+		SortBy:         request.SortBy,
+		Descending:     request.SortDirection == "desc",
+		Cursor:         request.Cursor,
+		Skip:           request.PageIndex * request.PageSize,
+		Limit:          request.PageSize + 1,
+	}
+	totalResults, err := paymentHandler.MongoConnection.CountSeatsMatching(c, query)
 	if err != nil {
 		return ListSeatReply{}, err
 	}
-	if totalResults == 0 {
-		return ListSeatReply{}, errors.New("no seats found")
-	}
-	pagedSeats, err := paymentHandler.MongoConnection.GetSeats(c, request.SubscriptionID, request.PageIndex, request.PageSize)
+	pagedSeats, err := paymentHandler.MongoConnection.FindSeats(c, query)
 	if err != nil {
 		return ListSeatReply{}, err
 	}
+	hasMore := len(pagedSeats) > request.PageSize
+	nextCursor := ""
+	if hasMore {
+		pagedSeats = pagedSeats[:request.PageSize]
+		nextCursor, err = mongomanager.EncodeSeatCursor(query, pagedSeats[len(pagedSeats)-1])
+		if err != nil {
+			return ListSeatReply{}, err
+		}
+	}
 	subscription, err := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
 	if err != nil {
 		return ListSeatReply{}, errors.New("subscription not found")
@@ -55,15 +83,13 @@ func (paymentHandler *PaymentHandler) GetSubscriptionListSeats(c context.Context
 		Seats:          pagedSeats,
 		PageIndex:      request.PageIndex,
 		TotalResults:   totalResults,
+		HasMore:        hasMore,
+		NextCursor:     nextCursor,
 	}
 	return reply, nil
 }
 
 func (paymentHandler *PaymentHandler) GetSubscriptionSeatDetail(c context.Context, tokenDetails firebasemanager.TokenDetails, request SeatDetailRequest) (SeatDetailReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
-	if err != nil {
-		return SeatDetailReply{}, err
-	}
 	selectedSeat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, request.UID)
 	if err != nil {
 		return SeatDetailReply{}, err
@@ -80,42 +106,43 @@ func (paymentHandler *PaymentHandler) GetSubscriptionSeatDetail(c context.Contex
 }
 
 func (paymentHandler *PaymentHandler) GetSubscriptionUpdateSeat(c context.Context, tokenDetails firebasemanager.TokenDetails, request UpdateSeatDetailRequest) (UpdateSeatDetailReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SeatUpdated.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
-	if err != nil {
-		return UpdateSeatDetailReply{}, err
-	}
-	err = paymentHandler.handleOwnerTransfer(c, tokenDetails, request.SeatUpdated)
-	if err != nil {
-		return UpdateSeatDetailReply{}, err
-	}
-	err = paymentHandler.MongoConnection.UpdateSeat(c, request.SeatUpdated)
-	if err != nil {
-		return UpdateSeatDetailReply{}, err
-	}
-	updatedSeat, err := paymentHandler.MongoConnection.GetSeat(c, request.SeatUpdated.SubscriptionID, request.SeatUpdated.UID)
+	rolesReply, err := paymentHandler.UpdateSeatRoles(c, tokenDetails, UpdateSeatRolesRequest{
+		SubscriptionID: request.SeatUpdated.SubscriptionID,
+		UID:            request.SeatUpdated.UID,
+		Roles:          request.SeatUpdated.Roles,
+	})
 	if err != nil {
 		return UpdateSeatDetailReply{}, err
 	}
 	reply := UpdateSeatDetailReply{
-		Seat: updatedSeat,
+		Seat: rolesReply.Seat,
 	}
 	return reply, nil
 }
 
 func (paymentHandler *PaymentHandler) GetSubscriptionAddSeat(c context.Context, tokenDetails firebasemanager.TokenDetails, request AddSeatRequest) (AddSeatReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
+	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, mongomanager.PermissionSeatsWrite)
 	if err != nil {
 		return AddSeatReply{}, err
 	}
 	if !IsValidEmail(request.EMail) {
 		return AddSeatReply{}, errors.New("E-Mail is invalid")
 	}
-	if len(request.Roles) == 0 {
+	roles := uniqueRoles(request.Roles)
+	if len(roles) == 0 {
 		return AddSeatReply{}, errors.New("no role selected")
 	}
-	if request.Roles[0] == mongomanager.RoleOwner {
+	if hasRole(roles, mongomanager.RoleOwner) {
 		return AddSeatReply{}, errors.New("cannot add user as owner")
 	}
+	actorSeat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, tokenDetails.UID)
+	if err != nil {
+		return AddSeatReply{}, err
+	}
+	err = paymentHandler.checkGrantableRoles(c, actorSeat, roles)
+	if err != nil {
+		return AddSeatReply{}, err
+	}
 	seats, err := paymentHandler.MongoConnection.GetAllSeats(c, request.SubscriptionID)
 	if err != nil {
 		return AddSeatReply{}, err
@@ -135,29 +162,41 @@ func (paymentHandler *PaymentHandler) GetSubscriptionAddSeat(c context.Context,
 	if !seatAvailable(seats, quantity) {
 		return AddSeatReply{}, errors.New("already used all seats")
 	}
-	userUID, err := paymentHandler.FirebaseConnection.InviteSeat(c, request.EMail)
+	seat, err := paymentHandler.inviteSeat(c, request.SubscriptionID, request.EMail, roles)
 	if err != nil {
 		return AddSeatReply{}, err
 	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatAdd, request.SubscriptionID, seat.UID, nil, seat)
+	reply := AddSeatReply{
+		SubscriptionID: request.SubscriptionID,
+		Success:        true,
+		EMail:          request.EMail,
+	}
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) inviteSeat(c context.Context, subscriptionID, email string, roles []mongomanager.Role) (mongomanager.Seat, error) {
+	userUID, err := paymentHandler.FirebaseConnection.InviteSeat(c, email)
+	if err != nil {
+		return mongomanager.Seat{}, err
+	}
 	emailVerified := false
+	invitedAt := time.Now()
 	seat := mongomanager.Seat{
-		SubscriptionID: request.SubscriptionID,
+		SubscriptionID: subscriptionID,
 		UID:            userUID,
-		EMail:          request.EMail,
+		EMail:          email,
 		EMailVerified:  &emailVerified,
-		Roles:          request.Roles,
+		Roles:          roles,
+		InvitedAt:      &invitedAt,
 	}
 	err = paymentHandler.MongoConnection.CreateSeat(c, seat)
 	if err != nil {
-		return AddSeatReply{}, err
+		return mongomanager.Seat{}, err
 	}
-	paymentHandler.Log.Error("Invite E-Mail should be sent to " + request.EMail)
-	reply := AddSeatReply{
-		SubscriptionID: request.SubscriptionID,
-		Success:        true,
-		EMail:          request.EMail,
-	}
-	return reply, nil
+	paymentHandler.verifiedTokens.Delete(userUID)
+	paymentHandler.log(c).Error("Invite E-Mail should be sent to " + email)
+	return seat, nil
 }
 
 func containsEmail(seats []mongomanager.Seat, email string) bool {
@@ -165,7 +204,7 @@ func containsEmail(seats []mongomanager.Seat, email string) bool {
 		return false
 	}
 	for _, seat := range seats {
-		if seat.EMail == email {
+		if strings.EqualFold(seat.EMail, email) {
 			return true
 		}
 	}
@@ -177,7 +216,7 @@ func seatAvailable(seats []mongomanager.Seat, quantity int64) bool {
 }
 
 func (paymentHandler *PaymentHandler) GetSubscriptionRemoveSeat(c context.Context, tokenDetails firebasemanager.TokenDetails, request DeleteSeatRequest) (DeleteSeatReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SeatToDelete.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
+	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SeatToDelete.SubscriptionID, mongomanager.PermissionSeatsWrite)
 	if err != nil {
 		return DeleteSeatReply{}, err
 	}
@@ -185,13 +224,14 @@ func (paymentHandler *PaymentHandler) GetSubscriptionRemoveSeat(c context.Contex
 	if err != nil {
 		return DeleteSeatReply{}, err
 	}
-	if mongomanager.ContainsRole(seatToRemove, []mongomanager.Role{mongomanager.RoleOwner}) {
+	if hasRole(seatToRemove.Roles, mongomanager.RoleOwner) {
 		return DeleteSeatReply{}, errors.New("cannot remove owner")
 	}
 	err = paymentHandler.MongoConnection.DeleteSeat(c, seatToRemove)
 	if err != nil {
 		return DeleteSeatReply{}, err
 	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatRemove, seatToRemove.SubscriptionID, seatToRemove.UID, seatToRemove, nil)
 	reply := DeleteSeatReply{
 		DeletedSeat: seatToRemove,
 		Success:     true,
@@ -199,6 +239,74 @@ func (paymentHandler *PaymentHandler) GetSubscriptionRemoveSeat(c context.Contex
 	return reply, nil
 }
 
+func (paymentHandler *PaymentHandler) DeleteSeats(c context.Context, tokenDetails firebasemanager.TokenDetails, request DeleteSeatsRequest) (DeleteSeatsReply, error) {
+	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, mongomanager.PermissionSeatsWrite)
+	if err != nil {
+		return DeleteSeatsReply{}, err
+	}
+	seats, err := paymentHandler.MongoConnection.GetSeatsByUIDs(c, request.SubscriptionID, request.UIDs)
+	if err != nil {
+		return DeleteSeatsReply{}, err
+	}
+	seatsByUID := make(map[string]mongomanager.Seat, len(seats))
+	for _, seat := range seats {
+		seatsByUID[seat.UID] = seat
+	}
+	results := make([]DeleteSeatResult, 0, len(request.UIDs))
+	var deletable []string
+	seen := make(map[string]bool)
+	for _, uid := range request.UIDs {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		result := DeleteSeatResult{UID: uid}
+		seat, ok := seatsByUID[uid]
+		switch {
+		case !ok:
+			result.Error = "seat not found"
+		case hasRole(seat.Roles, mongomanager.RoleOwner):
+			result.EMail = seat.EMail
+			result.Error = "cannot remove owner"
+		default:
+			result.EMail = seat.EMail
+			deletable = append(deletable, uid)
+		}
+		results = append(results, result)
+	}
+	_, err = paymentHandler.MongoConnection.DeleteSeats(c, request.SubscriptionID, deletable)
+	if err != nil {
+		return DeleteSeatsReply{}, err
+	}
+	remaining, err := paymentHandler.MongoConnection.GetSeatsByUIDs(c, request.SubscriptionID, deletable)
+	if err != nil {
+		paymentHandler.log(c).Error("Could not verify deleted seats", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+		return DeleteSeatsReply{}, err
+	}
+	notDeleted := make(map[string]bool, len(remaining))
+	for _, seat := range remaining {
+		notDeleted[seat.UID] = true
+	}
+	reply := DeleteSeatsReply{
+		SubscriptionID: request.SubscriptionID,
+		Results:        results,
+	}
+	for i := range reply.Results {
+		result := &reply.Results[i]
+		if result.Error == "" && notDeleted[result.UID] {
+			result.Error = "seat could not be deleted"
+		}
+		if result.Error == "" {
+			result.Success = true
+			reply.Deleted++
+			paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatRemove, request.SubscriptionID, result.UID, seatsByUID[result.UID], nil)
+		} else {
+			reply.Failed++
+		}
+	}
+	return reply, nil
+}
+
 func IsValidEmail(email string) bool {
 	_, err := mail.ParseAddress(email)
 	return err == nil
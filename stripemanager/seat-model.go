@@ -3,9 +3,19 @@ package stripemanager
 import "github.com/scalecloud/scalecloud.de-api/mongomanager"
 
 type ListSeatRequest struct {
-	SubscriptionID string `json:"subscriptionID" validate:"required"`
-	PageIndex      int    `json:"pageIndex" validate:"gte=0"`
-	PageSize       int    `json:"pageSize" validate:"gte=1"`
+	SubscriptionID string                     `json:"subscriptionID" validate:"required"`
+	PageIndex      int                        `json:"pageIndex" validate:"gte=0"`
+	PageSize       int                        `json:"pageSize" validate:"gte=1"`
+	Search         string                     `json:"search" validate:"max=254"`
+	Role           mongomanager.Role          `json:"role" validate:"omitempty,oneof=Owner Administrator User Billing"`
+	EMailVerified  *bool                      `json:"emailVerified"`
+	SortBy         mongomanager.SeatSortField `json:"sortBy" validate:"omitempty,oneof=email invitedAt joinedAt"`
+	SortDirection  string                     `json:"sortDirection" validate:"omitempty,oneof=asc desc"`
+	Cursor         string                     `json:"cursor"`
+}
+
+func (request ListSeatRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
 }
 
 type ListSeatReply struct {
@@ -13,13 +23,19 @@ type ListSeatReply struct {
 	MaxSeats       int64               `json:"maxSeats" validate:"required"`
 	Seats          []mongomanager.Seat `json:"seats" validate:"required"`
 	PageIndex      int                 `json:"pageIndex" validate:"gte=0"`
-	TotalResults   int64               `json:"totalResults" validate:"gte=1"`
+	TotalResults   int64               `json:"totalResults" validate:"gte=0"`
+	HasMore        bool                `json:"hasMore"`
+	NextCursor     string              `json:"nextCursor,omitempty"`
 }
 
 type AddSeatRequest struct {
 	SubscriptionID string              `json:"subscriptionID" validate:"required"`
 	EMail          string              `json:"email" validate:"required"`
-	Roles          []mongomanager.Role `json:"roles" validate:"required"`
+	Roles          []mongomanager.Role `json:"roles" validate:"required,min=1,dive,oneof=Owner Administrator User Billing"`
+}
+
+func (request AddSeatRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
 }
 
 type AddSeatReply struct {
@@ -32,6 +48,10 @@ type DeleteSeatRequest struct {
 	SeatToDelete mongomanager.Seat `json:"seatToDelete" validate:"required"`
 }
 
+func (request DeleteSeatRequest) ScopedSubscriptionID() string {
+	return request.SeatToDelete.SubscriptionID
+}
+
 type DeleteSeatReply struct {
 	DeletedSeat mongomanager.Seat `json:"deletedSeat" validate:"required"`
 	Success     bool              `json:"success" validate:"required"`
@@ -42,13 +62,27 @@ type SeatDetailRequest struct {
 	UID            string `json:"uid" validate:"required"`
 }
 
+func (request SeatDetailRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
+}
+
 type SeatDetailReply struct {
 	SelectedSeat mongomanager.Seat `json:"selectedSeat" validate:"required"`
 	MySeat       mongomanager.Seat `json:"mySeat" validate:"required"`
 }
 
 type UpdateSeatDetailRequest struct {
-	SeatUpdated mongomanager.Seat `json:"seatUpdated" validate:"required"`
+	SeatUpdated SeatRolesUpdate `json:"seatUpdated" validate:"required"`
+}
+
+func (request UpdateSeatDetailRequest) ScopedSubscriptionID() string {
+	return request.SeatUpdated.SubscriptionID
+}
+
+type SeatRolesUpdate struct {
+	SubscriptionID string              `json:"subscriptionID" validate:"required"`
+	UID            string              `json:"uid" validate:"required"`
+	Roles          []mongomanager.Role `json:"roles" validate:"required,min=1,dive,oneof=Owner Administrator User Billing"`
 }
 
 type UpdateSeatDetailReply struct {
@@ -62,3 +96,37 @@ type PermissionRequest struct {
 type PermissionReply struct {
 	MySeat mongomanager.Seat `json:"mySeat" validate:"required"`
 }
+
+type EffectivePermissionsReply struct {
+	SubscriptionID string                    `json:"subscriptionID" validate:"required"`
+	Roles          []mongomanager.Role       `json:"roles" validate:"required"`
+	Permissions    []mongomanager.Permission `json:"permissions" validate:"required"`
+}
+
+type DeleteSeatsRequest struct {
+	SubscriptionID string   `json:"subscriptionID" validate:"required"`
+	UIDs           []string `json:"uids" validate:"required,min=1,max=500,dive,required"`
+}
+
+type DeleteSeatResult struct {
+	UID     string `json:"uid" validate:"required"`
+	EMail   string `json:"email,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type DeleteSeatsReply struct {
+	SubscriptionID string             `json:"subscriptionID" validate:"required"`
+	Deleted        int                `json:"deleted" validate:"gte=0"`
+	Failed         int                `json:"failed" validate:"gte=0"`
+	Results        []DeleteSeatResult `json:"results" validate:"required"`
+}
+
+type LeaveSubscriptionRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+}
+
+type LeaveSubscriptionReply struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+	Success        bool   `json:"success" validate:"required"`
+}
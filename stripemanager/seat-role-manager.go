@@ -0,0 +1,137 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/scalecloud/scalecloud.de-api/mongomanager"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) UpdateSeatRoles(c context.Context, tokenDetails firebasemanager.TokenDetails, request UpdateSeatRolesRequest) (UpdateSeatRolesReply, error) {
+	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, mongomanager.PermissionSeatsWrite)
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	actorSeat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, tokenDetails.UID)
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	seat, err := paymentHandler.MongoConnection.GetSeat(c, request.SubscriptionID, request.UID)
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	return paymentHandler.updateSeatRoles(c, tokenDetails, actorSeat, seat, request.Roles)
+}
+
+func (paymentHandler *PaymentHandler) updateSeatRoles(c context.Context, tokenDetails firebasemanager.TokenDetails, actorSeat, seat mongomanager.Seat, requestedRoles []mongomanager.Role) (UpdateSeatRolesReply, error) {
+	roles := uniqueRoles(requestedRoles)
+	if len(roles) == 0 {
+		return UpdateSeatRolesReply{}, errors.New("no role selected")
+	}
+	isOwner := hasRole(seat.Roles, mongomanager.RoleOwner)
+	transferOwner := hasRole(roles, mongomanager.RoleOwner) && !isOwner
+	if isOwner && !hasRole(roles, mongomanager.RoleOwner) {
+		return UpdateSeatRolesReply{}, errors.New("owner role can only be changed by an owner transfer")
+	}
+	if transferOwner {
+		roles = withoutRole(roles, mongomanager.RoleOwner)
+		if len(roles) == 0 {
+			roles = seat.Roles
+		}
+	}
+	err := paymentHandler.checkGrantableRoles(c, actorSeat, changedRoles(seat.Roles, roles))
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	if transferOwner {
+		ownerSeat, err := paymentHandler.MongoConnection.GetOwnerSeat(c, seat.SubscriptionID)
+		if err != nil {
+			return UpdateSeatRolesReply{}, err
+		}
+		_, err = paymentHandler.requestOwnerTransfer(c, tokenDetails, ownerSeat, seat.UID)
+		if err != nil {
+			return UpdateSeatRolesReply{}, err
+		}
+	}
+	selfDemotion := actorSeat.UID == seat.UID && !isOwner &&
+		hasRole(seat.Roles, mongomanager.RoleAdministrator) && !hasRole(roles, mongomanager.RoleAdministrator)
+	err = paymentHandler.MongoConnection.WithTransaction(c, func(ctx context.Context) error {
+		if selfDemotion {
+			err := paymentHandler.MongoConnection.LockSeats(ctx, seat.SubscriptionID)
+			if err != nil {
+				return err
+			}
+			administrators, err := paymentHandler.MongoConnection.CountSeatsMatching(ctx, mongomanager.SeatQuery{
+				SubscriptionID: seat.SubscriptionID,
+				Role:           mongomanager.RoleAdministrator,
+			})
+			if err != nil {
+				return err
+			}
+			if administrators <= 1 {
+				return errors.New("cannot remove the administrator role from the last administrator")
+			}
+		}
+		return paymentHandler.MongoConnection.SetSeatRoles(ctx, seat.SubscriptionID, seat.UID, roles)
+	})
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	updatedSeat, err := paymentHandler.MongoConnection.GetSeat(c, seat.SubscriptionID, seat.UID)
+	if err != nil {
+		return UpdateSeatRolesReply{}, err
+	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSeatUpdate, seat.SubscriptionID, seat.UID, seat, updatedSeat)
+	reply := UpdateSeatRolesReply{
+		Seat:                 updatedSeat,
+		OwnerTransferPending: transferOwner,
+	}
+	return reply, nil
+}
+
+func (paymentHandler *PaymentHandler) checkGrantableRoles(c context.Context, actorSeat mongomanager.Seat, roles []mongomanager.Role) error {
+	for _, role := range roles {
+		if !mongomanager.CanGrantRole(actorSeat, role) {
+			paymentHandler.log(c).Warn("Seat is not allowed to grant role", zap.String("uid", actorSeat.UID), zap.String("role", string(role)), zap.String("subscriptionID", actorSeat.SubscriptionID))
+			return errors.New(http.StatusText(http.StatusForbidden))
+		}
+	}
+	return nil
+}
+
+func uniqueRoles(roles []mongomanager.Role) []mongomanager.Role {
+	var unique []mongomanager.Role
+	for _, role := range roles {
+		if !hasRole(unique, role) {
+			unique = append(unique, role)
+		}
+	}
+	return unique
+}
+
+func hasRole(roles []mongomanager.Role, role mongomanager.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func changedRoles(current, updated []mongomanager.Role) []mongomanager.Role {
+	var changed []mongomanager.Role
+	for _, role := range updated {
+		if !hasRole(current, role) {
+			changed = append(changed, role)
+		}
+	}
+	for _, role := range current {
+		if !hasRole(updated, role) && role != mongomanager.RoleOwner {
+			changed = append(changed, role)
+		}
+	}
+	return changed
+}
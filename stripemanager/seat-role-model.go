@@ -0,0 +1,14 @@
+package stripemanager
+
+import "github.com/scalecloud/scalecloud.de-api/mongomanager"
+
+type UpdateSeatRolesRequest struct {
+	SubscriptionID string              `json:"subscriptionID" validate:"required"`
+	UID            string              `json:"uid" validate:"required"`
+	Roles          []mongomanager.Role `json:"roles" validate:"required,min=1,dive,oneof=Owner Administrator User Billing"`
+}
+
+type UpdateSeatRolesReply struct {
+	Seat                 mongomanager.Seat `json:"seat" validate:"required"`
+	OwnerTransferPending bool              `json:"ownerTransferPending"`
+}
@@ -0,0 +1,73 @@
+package stripemanager
+
+import (
+	"context"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) SyncSeatEMailVerified(c context.Context, tokenDetails firebasemanager.TokenDetails) error {
+	if !tokenDetails.EMailVerified {
+		return nil
+	}
+	if issuedAt, ok := paymentHandler.verifiedTokens.Load(tokenDetails.UID); ok && issuedAt == tokenDetails.IssuedAt {
+		return nil
+	}
+	modified, err := paymentHandler.MongoConnection.MarkSeatsEMailVerified(c, tokenDetails.UID)
+	if err != nil {
+		return err
+	}
+	paymentHandler.verifiedTokens.Store(tokenDetails.UID, tokenDetails.IssuedAt)
+	if modified > 0 {
+		paymentHandler.log(c).Info("Synced seat email verification", zap.String("uid", tokenDetails.UID), zap.Int64("seats", modified))
+	}
+	return nil
+}
+
+// SyncUnverifiedSeats marks seats verified whose Firebase user record has a verified email, which
+// also covers users who verify without sending another request.
+func (paymentHandler *PaymentHandler) SyncUnverifiedSeats(ctx context.Context) error {
+	uids, err := paymentHandler.MongoConnection.GetUnverifiedSeatUIDs(ctx)
+	if err != nil {
+		return err
+	}
+	synced, err := paymentHandler.markVerifiedSeats(ctx, uids)
+	if err != nil {
+		return err
+	}
+	paymentHandler.log(ctx).Info("Synced unverified seats", zap.Int("uids", len(uids)), zap.Int64("seats", synced))
+	return nil
+}
+
+func (paymentHandler *PaymentHandler) syncUnverifiedSeatsOfSubscription(ctx context.Context, subscriptionID string) error {
+	uids, err := paymentHandler.MongoConnection.GetUnverifiedSeatUIDsOfSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	_, err = paymentHandler.markVerifiedSeats(ctx, uids)
+	return err
+}
+
+func (paymentHandler *PaymentHandler) markVerifiedSeats(ctx context.Context, uids []string) (int64, error) {
+	if len(uids) == 0 {
+		return 0, nil
+	}
+	verified, err := paymentHandler.FirebaseConnection.GetEMailVerified(ctx, uids)
+	if err != nil {
+		return 0, err
+	}
+	var synced int64
+	for uid, emailVerified := range verified {
+		if !emailVerified {
+			continue
+		}
+		modified, err := paymentHandler.MongoConnection.MarkSeatsEMailVerified(ctx, uid)
+		if err != nil {
+			paymentHandler.log(ctx).Error("Error syncing seat email verification", zap.String("uid", uid), zap.Error(err))
+			continue
+		}
+		synced += modified
+	}
+	return synced, nil
+}
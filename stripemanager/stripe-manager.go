@@ -2,15 +2,22 @@ package stripemanager
 
 import (
 	"context"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/scalecloud/scalecloud.de-api/emailmanager"
 	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
 	"github.com/scalecloud/scalecloud.de-api/mongomanager"
 	"github.com/scalecloud/scalecloud.de-api/newslettermanager"
+	"github.com/scalecloud/scalecloud.de-api/requestmanager"
 	"github.com/scalecloud/scalecloud.de-api/stripemanager/secret"
+	"github.com/stripe/stripe-go/v82"
 	"go.uber.org/zap"
 )
 
+const stripeHTTPTimeout = 80 * time.Second
+
 type StripeConnection struct {
 	Key            string
 	EndpointSecret string
@@ -24,6 +31,7 @@ type PaymentHandler struct {
 	EMailConnection      *emailmanager.EMailConnection
 	NewsletterConnection *newslettermanager.NewsletterConnection
 	Log                  *zap.Logger
+	verifiedTokens       sync.Map
 }
 
 func InitStripeConnection(ctx context.Context, log *zap.Logger) (*StripeConnection, error) {
@@ -41,5 +49,55 @@ func InitStripeConnection(ctx context.Context, log *zap.Logger) (*StripeConnecti
 		EndpointSecret: endpointSecret,
 		Log:            log.Named("stripeconnection"),
 	}
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		HTTPClient: &http.Client{
+			Timeout: stripeHTTPTimeout,
+			Transport: requestIDTransport{
+				base: http.DefaultTransport,
+				log:  stripeConnection.Log,
+			},
+		},
+	}))
 	return stripeConnection, nil
 }
+
+// requestIDTransport forwards the request ID of the API call to Stripe and logs it next to
+// Stripe's own request ID, so a request can be traced in the Stripe dashboard.
+type requestIDTransport struct {
+	base http.RoundTripper
+	log  *zap.Logger
+}
+
+func (transport requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID := requestmanager.GetRequestID(req.Context())
+	if requestID == "" {
+		return transport.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(requestmanager.HeaderRequestID, requestID)
+	res, err := transport.base.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	transport.log.Info("Stripe request", zap.String("requestID", requestID), zap.String("stripeRequestID", res.Header.Get("Request-Id")), zap.String("method", req.Method), zap.String("path", req.URL.Path), zap.Int("status", res.StatusCode))
+	return res, nil
+}
+
+func (paymentHandler *PaymentHandler) log(c context.Context) *zap.Logger {
+	return requestmanager.GetLogger(c, paymentHandler.Log)
+}
+
+// withRequest binds a mutating Stripe call to the API request: the request ID travels via the
+// context and the idempotency key is scoped to the client's Idempotency-Key.
+func withRequest(c context.Context, params *stripe.Params, operation string) {
+	params.Context = c
+	params.IdempotencyKey = idempotencyKey(c, operation)
+}
+
+func idempotencyKey(c context.Context, operation string) *string {
+	key := requestmanager.GetIdempotencyKey(c)
+	if key == "" {
+		return nil
+	}
+	return stripe.String(key + "-" + operation)
+}
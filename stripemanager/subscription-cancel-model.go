@@ -4,6 +4,10 @@ type SubscriptionCancelRequest struct {
 	SubscriptionID string `json:"subscriptionID" binding:"required"`
 }
 
+func (request SubscriptionCancelRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
+}
+
 type SubscriptionCancelReply struct {
 	SubscriptionID    string `json:"subscriptionID" validate:"required"`
 	CancelAtPeriodEnd *bool  `json:"cancel_at_period_end" validate:"required"`
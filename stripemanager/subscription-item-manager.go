@@ -2,19 +2,25 @@ package stripemanager
 
 import (
 	"github.com/stripe/stripe-go/v82"
-	"github.com/stripe/stripe-go/v82/subscriptionitem"
+	"github.com/stripe/stripe-go/v82/subscription"
 )
 
-func updateSubscriptionItem(subscriptionItemID string, quantity int64) (*stripe.SubscriptionItem, error) {
-	params := &stripe.SubscriptionItemParams{
-		Quantity: stripe.Int64(quantity),
+func updateSubscriptionItem(subscriptionID, subscriptionItemID string, quantity int64) (*stripe.Subscription, error) {
+	params := &stripe.SubscriptionParams{
+		Items: []*stripe.SubscriptionItemsParams{
+			{
+				ID:       stripe.String(subscriptionItemID),
+				Quantity: stripe.Int64(quantity),
+			},
+		},
+		AutomaticTax: automaticTax(),
 	}
-	si, err := subscriptionitem.Update(
-		subscriptionItemID,
+	sub, err := subscription.Update(
+		subscriptionID,
 		params,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return si, nil
+	return sub, nil
 }
@@ -17,10 +17,6 @@ func (stripeConnection *StripeConnection) GetSubscriptionByID(c context.Context,
 }
 
 func (paymentHandler *PaymentHandler) ResumeSubscription(c context.Context, tokenDetails firebasemanager.TokenDetails, request SubscriptionResumeRequest) (SubscriptionResumeReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
-	if err != nil {
-		return SubscriptionResumeReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 	sub, error := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
 	if error != nil {
@@ -30,6 +26,7 @@ func (paymentHandler *PaymentHandler) ResumeSubscription(c context.Context, toke
 		return SubscriptionResumeReply{}, errors.New("subscription is not canceled")
 	}
 	subscriptionParams := &stripe.SubscriptionParams{CancelAtPeriodEnd: stripe.Bool(false)}
+	withRequest(c, &subscriptionParams.Params, "resume-subscription")
 	result, err := subscription.Update(request.SubscriptionID, subscriptionParams)
 	if err != nil {
 		return SubscriptionResumeReply{}, err
@@ -38,24 +35,22 @@ func (paymentHandler *PaymentHandler) ResumeSubscription(c context.Context, toke
 		SubscriptionID:    result.ID,
 		CancelAtPeriodEnd: &result.CancelAtPeriodEnd,
 	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSubscriptionResume, request.SubscriptionID, request.SubscriptionID, map[string]interface{}{"cancelAtPeriodEnd": sub.CancelAtPeriodEnd}, reply)
 	return reply, nil
 }
 
 func (paymentHandler *PaymentHandler) CancelSubscription(c context.Context, tokenDetails firebasemanager.TokenDetails, request SubscriptionCancelRequest) (SubscriptionCancelReply, error) {
-	err := paymentHandler.MongoConnection.HasPermission(c, tokenDetails, request.SubscriptionID, []mongomanager.Role{mongomanager.RoleAdministrator})
-	if err != nil {
-		return SubscriptionCancelReply{}, err
-	}
 	stripe.Key = paymentHandler.StripeConnection.Key
 	sub, error := paymentHandler.StripeConnection.GetSubscriptionByID(c, request.SubscriptionID)
 	if error != nil {
 		return SubscriptionCancelReply{}, errors.New("subscription not found")
 	}
 	if sub.CancelAtPeriodEnd {
-		paymentHandler.Log.Info("Subscription is already canceled", zap.String("status", string(sub.Status)))
+		paymentHandler.log(c).Info("Subscription is already canceled", zap.String("status", string(sub.Status)))
 		return SubscriptionCancelReply{}, errors.New("subscription is already canceled")
 	}
 	subscriptionParams := &stripe.SubscriptionParams{CancelAtPeriodEnd: stripe.Bool(true)}
+	withRequest(c, &subscriptionParams.Params, "cancel-subscription")
 	result, err := subscription.Update(request.SubscriptionID, subscriptionParams)
 	if err != nil {
 		return SubscriptionCancelReply{}, err
@@ -65,5 +60,6 @@ func (paymentHandler *PaymentHandler) CancelSubscription(c context.Context, toke
 		CancelAtPeriodEnd: &result.CancelAtPeriodEnd,
 		CancelAt:          result.CancelAt,
 	}
+	paymentHandler.audit(c, tokenDetails, mongomanager.AuditActionSubscriptionCancel, request.SubscriptionID, request.SubscriptionID, map[string]interface{}{"cancelAtPeriodEnd": sub.CancelAtPeriodEnd}, reply)
 	return reply, nil
 }
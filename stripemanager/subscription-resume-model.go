@@ -4,6 +4,10 @@ type SubscriptionResumeRequest struct {
 	SubscriptionID string `json:"subscriptionID" binding:"required"`
 }
 
+func (request SubscriptionResumeRequest) ScopedSubscriptionID() string {
+	return request.SubscriptionID
+}
+
 type SubscriptionResumeReply struct {
 	SubscriptionID    string `json:"subscriptionID" validate:"required"`
 	CancelAtPeriodEnd *bool  `json:"cancel_at_period_end" validate:"required"`
@@ -0,0 +1,243 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customer"
+	"github.com/stripe/stripe-go/v82/taxid"
+	"go.uber.org/zap"
+)
+
+const (
+	sellerCountry        = "DE"
+	metadataCompanyName  = "companyName"
+	invoiceFieldCompany  = "Company"
+	customerTaxIDsExpand = "tax_ids"
+)
+
+var euVATPatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^ATU\d{8}$`),
+	"BE": regexp.MustCompile(`^BE[01]\d{9}$`),
+	"BG": regexp.MustCompile(`^BG\d{9,10}$`),
+	"CY": regexp.MustCompile(`^CY\d{8}[A-Z]$`),
+	"CZ": regexp.MustCompile(`^CZ\d{8,10}$`),
+	"DE": regexp.MustCompile(`^DE\d{9}$`),
+	"DK": regexp.MustCompile(`^DK\d{8}$`),
+	"EE": regexp.MustCompile(`^EE\d{9}$`),
+	"EL": regexp.MustCompile(`^EL\d{9}$`),
+	"ES": regexp.MustCompile(`^ES[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^FI\d{8}$`),
+	"FR": regexp.MustCompile(`^FR[A-HJ-NP-Z0-9]{2}\d{9}$`),
+	"HR": regexp.MustCompile(`^HR\d{11}$`),
+	"HU": regexp.MustCompile(`^HU\d{8}$`),
+	"IE": regexp.MustCompile(`^IE(\d{7}[A-W][A-IW]?|\d[A-Z+*]\d{5}[A-W])$`),
+	"IT": regexp.MustCompile(`^IT\d{11}$`),
+	"LT": regexp.MustCompile(`^LT(\d{9}|\d{12})$`),
+	"LU": regexp.MustCompile(`^LU\d{8}$`),
+	"LV": regexp.MustCompile(`^LV\d{11}$`),
+	"MT": regexp.MustCompile(`^MT\d{8}$`),
+	"NL": regexp.MustCompile(`^NL\d{9}B\d{2}$`),
+	"PL": regexp.MustCompile(`^PL\d{10}$`),
+	"PT": regexp.MustCompile(`^PT\d{9}$`),
+	"RO": regexp.MustCompile(`^RO\d{2,10}$`),
+	"SE": regexp.MustCompile(`^SE\d{12}$`),
+	"SI": regexp.MustCompile(`^SI\d{8}$`),
+	"SK": regexp.MustCompile(`^SK\d{10}$`),
+}
+
+func euCountryOfVATPrefix(prefix string) string {
+	if prefix == "EL" {
+		return "GR"
+	}
+	return prefix
+}
+
+func isEUCountry(country string) bool {
+	if country == "GR" {
+		return true
+	}
+	_, ok := euVATPatterns[country]
+	return ok && country != "EL"
+}
+
+func normalizeTaxID(taxID TaxID) TaxID {
+	value := strings.ToUpper(strings.TrimSpace(taxID.Value))
+	if taxID.Type == TaxIDTypeEUVAT {
+		value = strings.NewReplacer(" ", "", ".", "", "-", "").Replace(value)
+	} else {
+		value = strings.ReplaceAll(value, " ", "")
+	}
+	return TaxID{Type: taxID.Type, Value: value}
+}
+
+func validateTaxIDs(taxIDs []TaxID) ([]TaxID, error) {
+	normalized := make([]TaxID, 0, len(taxIDs))
+	seen := map[TaxID]bool{}
+	for _, taxID := range taxIDs {
+		taxID = normalizeTaxID(taxID)
+		switch taxID.Type {
+		case TaxIDTypeEUVAT:
+			if len(taxID.Value) < 2 {
+				return nil, errors.New("invalid eu_vat tax ID: " + taxID.Value)
+			}
+			pattern, ok := euVATPatterns[taxID.Value[:2]]
+			if !ok || !pattern.MatchString(taxID.Value) {
+				return nil, errors.New("invalid eu_vat tax ID: " + taxID.Value)
+			}
+		default:
+			return nil, errors.New("unsupported tax ID type: " + string(taxID.Type))
+		}
+		if seen[taxID] {
+			continue
+		}
+		seen[taxID] = true
+		normalized = append(normalized, taxID)
+	}
+	return normalized, nil
+}
+
+func taxExemptFor(country string, taxIDs []*stripe.TaxID) TaxExempt {
+	if country == sellerCountry || !isEUCountry(country) {
+		return TaxExemptNone
+	}
+	for _, taxID := range taxIDs {
+		if taxID.Type != stripe.TaxIDTypeEUVAT || len(taxID.Value) < 2 || euCountryOfVATPrefix(taxID.Value[:2]) != country {
+			continue
+		}
+		if taxID.Verification != nil && taxID.Verification.Status == stripe.TaxIDVerificationStatusVerified {
+			return TaxExemptReverse
+		}
+	}
+	return TaxExemptNone
+}
+
+func customerTaxIDs(customer *stripe.Customer) []*stripe.TaxID {
+	if customer.TaxIDs == nil {
+		return nil
+	}
+	return customer.TaxIDs.Data
+}
+
+func taxIDDetailsOf(customer *stripe.Customer) []TaxIDDetail {
+	details := []TaxIDDetail{}
+	for _, taxID := range customerTaxIDs(customer) {
+		detail := TaxIDDetail{
+			Type:  TaxIDType(taxID.Type),
+			Value: taxID.Value,
+		}
+		if taxID.Verification != nil {
+			detail.Verification = string(taxID.Verification.Status)
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+func getCustomerWithTaxIDs(ctx context.Context, customerID string) (*stripe.Customer, error) {
+	params := &stripe.CustomerParams{}
+	params.Context = ctx
+	params.AddExpand(customerTaxIDsExpand)
+	cus, err := customer.Get(customerID, params)
+	if err != nil {
+		return &stripe.Customer{}, errors.New("customer not found")
+	}
+	return cus, nil
+}
+
+type taxIDChanges struct {
+	kept    []*stripe.TaxID
+	created []*stripe.TaxID
+	stale   []*stripe.TaxID
+}
+
+func (changes taxIDChanges) result() []*stripe.TaxID {
+	return append(append([]*stripe.TaxID{}, changes.kept...), changes.created...)
+}
+
+func (paymentHandler *PaymentHandler) createTaxIDs(c context.Context, cus *stripe.Customer, taxIDs []TaxID) (taxIDChanges, error) {
+	wanted := map[TaxID]bool{}
+	for _, taxID := range taxIDs {
+		wanted[taxID] = true
+	}
+	changes := taxIDChanges{}
+	for _, existing := range customerTaxIDs(cus) {
+		key := TaxID{Type: TaxIDType(existing.Type), Value: existing.Value}
+		switch {
+		case existing.Type != stripe.TaxIDTypeEUVAT:
+			changes.kept = append(changes.kept, existing)
+		case wanted[key]:
+			delete(wanted, key)
+			changes.kept = append(changes.kept, existing)
+		default:
+			changes.stale = append(changes.stale, existing)
+		}
+	}
+	for _, taxID := range taxIDs {
+		if !wanted[taxID] {
+			continue
+		}
+		params := &stripe.TaxIDParams{
+			Customer: stripe.String(cus.ID),
+			Type:     stripe.String(string(taxID.Type)),
+			Value:    stripe.String(taxID.Value),
+		}
+		withRequest(c, &params.Params, "create-tax-id-"+string(taxID.Type)+"-"+taxID.Value)
+		created, err := taxid.New(params)
+		if err != nil {
+			paymentHandler.log(c).Warn("Error creating tax ID", zap.String("customerID", cus.ID), zap.String("type", string(taxID.Type)), zap.Error(err))
+			paymentHandler.deleteTaxIDs(c, cus.ID, changes.created)
+			return taxIDChanges{}, errors.New("tax ID was rejected: " + taxID.Value)
+		}
+		changes.created = append(changes.created, created)
+	}
+	return changes, nil
+}
+
+func (paymentHandler *PaymentHandler) deleteTaxIDs(c context.Context, customerID string, taxIDs []*stripe.TaxID) map[string]bool {
+	deleted := map[string]bool{}
+	for _, taxID := range taxIDs {
+		_, err := taxid.Del(taxID.ID, &stripe.TaxIDParams{Customer: stripe.String(customerID)})
+		if err != nil {
+			paymentHandler.log(c).Error("Error deleting tax ID", zap.String("customerID", customerID), zap.String("taxID", taxID.ID), zap.Error(err))
+			continue
+		}
+		deleted[taxID.ID] = true
+	}
+	return deleted
+}
+
+// TaxIDChanged recomputes the tax exemption once Stripe has verified or removed a VAT ID,
+// because verification finishes asynchronously after the billing address was saved.
+func (paymentHandler *PaymentHandler) TaxIDChanged(c context.Context, taxID stripe.TaxID) error {
+	if taxID.Customer == nil || taxID.Customer.ID == "" {
+		return errors.New("tax ID has no customer")
+	}
+	stripe.Key = paymentHandler.StripeConnection.Key
+	cus, err := getCustomerWithTaxIDs(c, taxID.Customer.ID)
+	if err != nil {
+		return err
+	}
+	country := ""
+	if cus.Address != nil {
+		country = cus.Address.Country
+	}
+	taxExempt := taxExemptFor(country, customerTaxIDs(cus))
+	if string(cus.TaxExempt) == string(taxExempt) {
+		return nil
+	}
+	params := &stripe.CustomerParams{
+		TaxExempt: stripe.String(string(taxExempt)),
+	}
+	params.Context = c
+	_, err = customer.Update(cus.ID, params)
+	if err != nil {
+		paymentHandler.log(c).Error("Error updating tax exemption", zap.String("customerID", cus.ID), zap.Error(err))
+		return err
+	}
+	paymentHandler.log(c).Info("Updated tax exemption", zap.String("customerID", cus.ID), zap.String("taxID", taxID.ID), zap.String("taxExempt", string(taxExempt)))
+	return nil
+}
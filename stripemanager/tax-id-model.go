@@ -0,0 +1,26 @@
+package stripemanager
+
+type TaxIDType string
+
+const (
+	TaxIDTypeEUVAT TaxIDType = "eu_vat"
+)
+
+type TaxExempt string
+
+const (
+	TaxExemptNone    TaxExempt = "none"
+	TaxExemptExempt  TaxExempt = "exempt"
+	TaxExemptReverse TaxExempt = "reverse"
+)
+
+type TaxID struct {
+	Type  TaxIDType `json:"type" validate:"required,oneof=eu_vat"`
+	Value string    `json:"value" validate:"required,max=32"`
+}
+
+type TaxIDDetail struct {
+	Type         TaxIDType `json:"type" validate:"required"`
+	Value        string    `json:"value" validate:"required"`
+	Verification string    `json:"verification,omitempty"`
+}
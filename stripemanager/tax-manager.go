@@ -0,0 +1,102 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/customer"
+	"github.com/stripe/stripe-go/v82/tax/calculation"
+	"go.uber.org/zap"
+)
+
+var ErrBillingAddressRequired = errors.New("a valid billing address is required before checkout")
+var ErrTaxBehaviorMissing = errors.New("price has no tax behavior")
+
+type priceTotals struct {
+	exclusiveTax int64
+	inclusiveTax int64
+}
+
+func automaticTax() *stripe.SubscriptionAutomaticTaxParams {
+	return &stripe.SubscriptionAutomaticTaxParams{
+		Enabled: stripe.Bool(true),
+	}
+}
+
+func requireTaxBehavior(price *stripe.Price) error {
+	switch price.TaxBehavior {
+	case stripe.PriceTaxBehaviorExclusive, stripe.PriceTaxBehaviorInclusive:
+		return nil
+	}
+	return ErrTaxBehaviorMissing
+}
+
+func hasCompleteAddress(cus *stripe.Customer) bool {
+	return cus.Address != nil &&
+		cus.Address.Line1 != "" &&
+		cus.Address.PostalCode != "" &&
+		cus.Address.City != "" &&
+		cus.Address.Country != ""
+}
+
+func (stripeConnection *StripeConnection) hasTaxableBillingAddress(ctx context.Context, customerID string) (bool, error) {
+	stripe.Key = stripeConnection.Key
+	params := &stripe.CustomerParams{}
+	params.Context = ctx
+	params.AddExpand("tax")
+	cus, err := customer.Get(customerID, params)
+	if err != nil {
+		return false, errors.New("customer not found")
+	}
+	if !hasCompleteAddress(cus) || cus.Tax == nil {
+		return false, nil
+	}
+	switch cus.Tax.AutomaticTax {
+	case stripe.CustomerTaxAutomaticTaxFailed, stripe.CustomerTaxAutomaticTaxUnrecognizedLocation:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (stripeConnection *StripeConnection) calculatePriceTotals(ctx context.Context, price *stripe.Price, amount int64, productID string, customerID string) (priceTotals, error) {
+	stripe.Key = stripeConnection.Key
+	err := requireTaxBehavior(price)
+	if err != nil {
+		stripeConnection.Log.Error("Price has no tax behavior", zap.String("priceID", price.ID))
+		return priceTotals{}, err
+	}
+	params := &stripe.TaxCalculationParams{
+		Currency: stripe.String(string(price.Currency)),
+		LineItems: []*stripe.TaxCalculationLineItemParams{
+			{
+				Amount:      stripe.Int64(amount),
+				Product:     stripe.String(productID),
+				Quantity:    stripe.Int64(1),
+				Reference:   stripe.String(price.ID),
+				TaxBehavior: stripe.String(string(price.TaxBehavior)),
+			},
+		},
+	}
+	if customerID != "" {
+		params.Customer = stripe.String(customerID)
+	} else {
+		params.CustomerDetails = &stripe.TaxCalculationCustomerDetailsParams{
+			Address: &stripe.AddressParams{
+				Country: stripe.String(sellerCountry),
+			},
+			AddressSource: stripe.String("billing"),
+		}
+	}
+	params.Context = ctx
+	result, err := calculation.New(params)
+	if err != nil {
+		stripeConnection.Log.Warn("Error calculating tax", zap.String("priceID", price.ID), zap.String("customerID", customerID), zap.Error(err))
+		return priceTotals{}, errors.New("tax calculation failed")
+	}
+	tax := result.TaxAmountExclusive + result.TaxAmountInclusive
+	return priceTotals{
+		exclusiveTax: result.AmountTotal - tax,
+		inclusiveTax: result.AmountTotal,
+	}, nil
+}
@@ -12,12 +12,12 @@ import (
 
 func (paymentHandler *PaymentHandler) getTrialDaysForCustomer(c context.Context, quantity int64, paymentMethod *stripe.PaymentMethod, product *stripe.Product, customer *stripe.Customer) (int64, error) {
 	if quantity != 1 {
-		paymentHandler.Log.Info("Quantity is not 1 therefore no trial period is possible.", zap.Int64("Quantity", quantity))
+		paymentHandler.log(c).Info("Quantity is not 1 therefore no trial period is possible.", zap.Int64("Quantity", quantity))
 		return -1, nil
 	}
 	err := paymentHandler.hadTrialBefore(c, paymentMethod, product, customer)
 	if err != nil {
-		paymentHandler.Log.Info("Customer had trial before. No trial period is possible.", zap.Error(err))
+		paymentHandler.log(c).Info("Customer had trial before. No trial period is possible.", zap.Error(err))
 		return -1, nil
 	}
 	stripe.Key = paymentHandler.StripeConnection.Key
@@ -28,7 +28,7 @@ func (paymentHandler *PaymentHandler) getTrialDaysForCustomer(c context.Context,
 	}
 	iTrialPeriodDays, err := strconv.ParseInt(trialPeriodDays, 10, 64)
 	if err != nil {
-		paymentHandler.Log.Error("Error converting trialPeriodDays to int", zap.Error(err))
+		paymentHandler.log(c).Error("Error converting trialPeriodDays to int", zap.Error(err))
 		return 0, errors.New("error converting trialPeriodDays")
 	}
 	return iTrialPeriodDays, nil
@@ -76,7 +76,7 @@ func (paymentHandler *PaymentHandler) hadTrialBefore(ctx context.Context, paymen
 		return err
 	}
 	if trialSearch == (mongomanager.Trial{}) {
-		paymentHandler.Log.Info("Customer did not use trial before.", zap.String("CustomerID", customer.ID), zap.String("ProductType", productType))
+		paymentHandler.log(ctx).Info("Customer did not use trial before.", zap.String("CustomerID", customer.ID), zap.String("ProductType", productType))
 		return nil
 	} else if trialSearch.CustomerID != "" {
 		return errors.New("CustomerID matched. Customer used trial before. CustomerID: " + trialSearch.CustomerID + " ProductType:" + productType)
@@ -87,7 +87,7 @@ func (paymentHandler *PaymentHandler) hadTrialBefore(ctx context.Context, paymen
 	} else if trialSearch.PaymentSEPAFingerprint != "" {
 		return errors.New("PaymentSEPAFingerprint matched. Customer used trial before. PaymentSEPAFingerprint: " + trialSearch.PaymentSEPAFingerprint + " ProductType:" + productType)
 	} else {
-		paymentHandler.Log.Warn("No match found for trial search. This should not happen.", zap.Any("TrialSearch", trialSearch))
+		paymentHandler.log(ctx).Warn("No match found for trial search. This should not happen.", zap.Any("TrialSearch", trialSearch))
 	}
 	return nil
 }
@@ -0,0 +1,82 @@
+package stripemanager
+
+import (
+	"context"
+	"errors"
+
+	"github.com/scalecloud/scalecloud.de-api/firebasemanager"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/invoice"
+	"go.uber.org/zap"
+)
+
+func (paymentHandler *PaymentHandler) GetUpcomingInvoice(c context.Context, tokenDetails firebasemanager.TokenDetails, request UpcomingInvoiceRequest) (UpcomingInvoiceReply, error) {
+	stripe.Key = paymentHandler.StripeConnection.Key
+	params := &stripe.InvoiceCreatePreviewParams{
+		Subscription: stripe.String(request.SubscriptionID),
+	}
+	params.AddExpand("total_discount_amounts.discount")
+	preview, err := invoice.CreatePreview(params)
+	if err != nil {
+		paymentHandler.log(c).Warn("Error previewing upcoming invoice", zap.String("subscriptionID", request.SubscriptionID), zap.Error(err))
+		return UpcomingInvoiceReply{}, errors.New("no upcoming invoice")
+	}
+	reply := UpcomingInvoiceReply{
+		SubscriptionID: request.SubscriptionID,
+		Currency:       string(preview.Currency),
+		Lines:          []UpcomingInvoiceLine{},
+		Subtotal:       preview.Subtotal,
+		Discounts:      []UpcomingInvoiceDiscount{},
+		Total:          preview.Total,
+		AmountDue:      preview.AmountDue,
+		Date:           preview.NextPaymentAttempt,
+	}
+	if reply.Date == 0 {
+		reply.Date = preview.PeriodEnd
+	}
+	if preview.Lines != nil {
+		for _, line := range preview.Lines.Data {
+			reply.Lines = append(reply.Lines, toUpcomingInvoiceLine(line))
+		}
+	}
+	for _, discountAmount := range preview.TotalDiscountAmounts {
+		reply.TotalDiscount += discountAmount.Amount
+		reply.Discounts = append(reply.Discounts, UpcomingInvoiceDiscount{
+			Name:   discountName(discountAmount.Discount),
+			Amount: discountAmount.Amount,
+		})
+	}
+	for _, tax := range preview.TotalTaxes {
+		reply.Tax += tax.Amount
+	}
+	return reply, nil
+}
+
+func toUpcomingInvoiceLine(line *stripe.InvoiceLineItem) UpcomingInvoiceLine {
+	upcomingLine := UpcomingInvoiceLine{
+		Description: line.Description,
+		Quantity:    line.Quantity,
+		Amount:      line.Amount,
+	}
+	if line.Period != nil {
+		upcomingLine.PeriodStart = line.Period.Start
+		upcomingLine.PeriodEnd = line.Period.End
+	}
+	if line.Parent != nil && line.Parent.SubscriptionItemDetails != nil {
+		upcomingLine.Proration = line.Parent.SubscriptionItemDetails.Proration
+	}
+	return upcomingLine
+}
+
+func discountName(discount *stripe.Discount) string {
+	if discount == nil {
+		return ""
+	}
+	if discount.Coupon != nil && discount.Coupon.Name != "" {
+		return discount.Coupon.Name
+	}
+	if discount.Coupon != nil {
+		return discount.Coupon.ID
+	}
+	return discount.ID
+}
@@ -0,0 +1,32 @@
+package stripemanager
+
+type UpcomingInvoiceRequest struct {
+	SubscriptionID string `json:"subscriptionID" validate:"required"`
+}
+
+type UpcomingInvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    int64  `json:"quantity" validate:"gte=0"`
+	Amount      int64  `json:"amount"`
+	PeriodStart int64  `json:"periodStart" validate:"gte=0"`
+	PeriodEnd   int64  `json:"periodEnd" validate:"gte=0"`
+	Proration   bool   `json:"proration"`
+}
+
+type UpcomingInvoiceDiscount struct {
+	Name   string `json:"name"`
+	Amount int64  `json:"amount" validate:"gte=0"`
+}
+
+type UpcomingInvoiceReply struct {
+	SubscriptionID string                    `json:"subscriptionID" validate:"required"`
+	Currency       string                    `json:"currency" validate:"required"`
+	Lines          []UpcomingInvoiceLine     `json:"lines" validate:"required"`
+	Subtotal       int64                     `json:"subtotal"`
+	Discounts      []UpcomingInvoiceDiscount `json:"discounts" validate:"required"`
+	TotalDiscount  int64                     `json:"totalDiscount" validate:"gte=0"`
+	Tax            int64                     `json:"tax" validate:"gte=0"`
+	Total          int64                     `json:"total"`
+	AmountDue      int64                     `json:"amountDue" validate:"gte=0"`
+	Date           int64                     `json:"date" validate:"gte=0"`
+}